@@ -0,0 +1,110 @@
+package cachex
+
+import (
+	"context"
+	ccachex "github.com/meiguonet/mgboot-go-common/cachex"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedCache counts the lookups for the metrics and, when traced is set, records a span for each operation
+type tracedCache struct {
+	ctx    context.Context
+	name   string
+	store  ccachex.ICache
+	traced bool
+}
+
+func newTracedCache(ctx context.Context, name string, store ccachex.ICache, traced bool) *tracedCache {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return &tracedCache{ctx: ctx, name: name, store: store, traced: traced}
+}
+
+func (c *tracedCache) Get(key string, defaultValue ...interface{}) interface{} {
+	span := c.startSpan("cache.get", attribute.String("cache.key", key))
+	defer span.End()
+	value := c.store.Get(key)
+	span.SetAttributes(attribute.Bool("cache.hit", value != nil))
+	observeCacheLookup(c.name, value != nil)
+
+	if value == nil && len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+
+	return value
+}
+
+func (c *tracedCache) Set(key string, value interface{}, ttl ...interface{}) bool {
+	span := c.startSpan("cache.set", attribute.String("cache.key", key))
+	defer span.End()
+	return c.store.Set(key, value, ttl...)
+}
+
+func (c *tracedCache) Delete(key string) bool {
+	span := c.startSpan("cache.delete", attribute.String("cache.key", key))
+	defer span.End()
+	return c.store.Delete(key)
+}
+
+func (c *tracedCache) Clear() bool {
+	span := c.startSpan("cache.clear")
+	defer span.End()
+	return c.store.Clear()
+}
+
+func (c *tracedCache) GetMultiple(keys []string, defaultValue ...interface{}) []interface{} {
+	span := c.startSpan("cache.get_multiple", attribute.StringSlice("cache.keys", keys))
+	defer span.End()
+	values := c.store.GetMultiple(keys)
+
+	for i, value := range values {
+		observeCacheLookup(c.name, value != nil)
+
+		if value == nil && len(defaultValue) > 0 {
+			values[i] = defaultValue[0]
+		}
+	}
+
+	return values
+}
+
+func (c *tracedCache) SetMultiple(entries []map[string]interface{}, ttl ...interface{}) bool {
+	span := c.startSpan("cache.set_multiple", attribute.Int("cache.entries", len(entries)))
+	defer span.End()
+	return c.store.SetMultiple(entries, ttl...)
+}
+
+func (c *tracedCache) DeleteMultiple(keys []string) bool {
+	span := c.startSpan("cache.delete_multiple", attribute.StringSlice("cache.keys", keys))
+	defer span.End()
+	return c.store.DeleteMultiple(keys)
+}
+
+func (c *tracedCache) Has(key string) bool {
+	span := c.startSpan("cache.has", attribute.String("cache.key", key))
+	defer span.End()
+	found := c.store.Has(key)
+	span.SetAttributes(attribute.Bool("cache.hit", found))
+	observeCacheLookup(c.name, found)
+	return found
+}
+
+func (c *tracedCache) startSpan(name string, attrs ...attribute.KeyValue) trace.Span {
+	if !c.traced {
+		return trace.SpanFromContext(context.Background())
+	}
+
+	attrs = append(attrs, attribute.String("cache.store", c.name))
+	_, span := otel.Tracer("github.com/meiguonet/mgboot-go-fiber/cachex").Start(
+		c.ctx,
+		name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+
+	return span
+}
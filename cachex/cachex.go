@@ -1,7 +1,7 @@
 package cachex
 
 import (
-	"fmt"
+	"context"
 	ccachex "github.com/meiguonet/mgboot-go-common/cachex"
 	"github.com/meiguonet/mgboot-go-common/util/fsx"
 	"github.com/meiguonet/mgboot-go-common/util/stringx"
@@ -18,6 +18,7 @@ var cacheKeyRedismqDelayable = "redismq.delayable"
 var defaultCacheStore string
 var gocache *cache.Cache
 var cacheStores = map[string]ccachex.ICache{}
+var tracingEnabled bool
 
 func CacheDir(dir ...string) string {
 	if len(dir) > 0 {
@@ -141,11 +142,38 @@ func WithFileCache() {
 	cacheStores["file"] = newFileCache()
 }
 
+// TracingEnabled is switched on by mgboot.WithTracingSettings, only then the operations of the stores
+// returned by StoreWithContext are traced
+func TracingEnabled(enabled ...bool) bool {
+	if len(enabled) > 0 {
+		tracingEnabled = enabled[0]
+	}
+
+	return tracingEnabled
+}
+
 func Store(name string) ccachex.ICache {
 	if c, ok := cacheStores[name]; ok {
+		if metricsEnabled() {
+			return newTracedCache(context.Background(), name, c, false)
+		}
+
+		return c
+	}
+
+	return &noopCache{}
+}
+
+// StoreWithContext returns the named store whose operations are traced as children of the span in ctx
+// when tracing is enabled.
+func StoreWithContext(ctx context.Context, name string) ccachex.ICache {
+	if c, ok := cacheStores[name]; ok {
+		if tracingEnabled || metricsEnabled() {
+			return newTracedCache(ctx, name, c, tracingEnabled)
+		}
+
 		return c
 	}
 
-	fmt.Println("is noop cache")
 	return &noopCache{}
 }
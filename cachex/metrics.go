@@ -0,0 +1,47 @@
+package cachex
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+)
+
+var cacheRequestsTotal *prometheus.CounterVec
+var cacheMetricsMu = &sync.RWMutex{}
+
+// NewMetricsCollector creates the cache hit/miss counter, lookups made through Store and StoreWithContext are counted once it exists.
+func NewMetricsCollector(namespace string) prometheus.Collector {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Total number of cache lookups by store and result.",
+	}, []string{"store", "result"})
+
+	cacheMetricsMu.Lock()
+	cacheRequestsTotal = counter
+	cacheMetricsMu.Unlock()
+	return counter
+}
+
+func metricsEnabled() bool {
+	cacheMetricsMu.RLock()
+	defer cacheMetricsMu.RUnlock()
+	return cacheRequestsTotal != nil
+}
+
+func observeCacheLookup(store string, hit bool) {
+	cacheMetricsMu.RLock()
+	counter := cacheRequestsTotal
+	cacheMetricsMu.RUnlock()
+
+	if counter == nil {
+		return
+	}
+
+	result := "miss"
+
+	if hit {
+		result = "hit"
+	}
+
+	counter.WithLabelValues(store, result).Inc()
+}
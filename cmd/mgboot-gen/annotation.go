@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type annotation struct {
+	name       string
+	positional []string
+	named      map[string]string
+}
+
+func (a *annotation) value(keys ...string) string {
+	for _, key := range keys {
+		if v, ok := a.named[key]; ok {
+			return v
+		}
+	}
+
+	if len(a.positional) > 0 {
+		return a.positional[0]
+	}
+
+	return ""
+}
+
+func (a *annotation) values(keys ...string) []string {
+	for _, key := range keys {
+		if v, ok := a.named[key]; ok {
+			return []string{v}
+		}
+	}
+
+	return a.positional
+}
+
+// parseAnnotations collects the @Xxx(...) lines of a doc comment, other lines are ignored
+func parseAnnotations(lines []string) ([]*annotation, error) {
+	annotations := make([]*annotation, 0)
+
+	for _, line := range lines {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(line, "//"), "/*"))
+
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+
+		a, err := parseAnnotation(line)
+
+		if err != nil {
+			return nil, err
+		}
+
+		annotations = append(annotations, a)
+	}
+
+	return annotations, nil
+}
+
+func parseAnnotation(line string) (*annotation, error) {
+	line = strings.TrimPrefix(line, "@")
+	a := &annotation{named: map[string]string{}}
+	idx := strings.Index(line, "(")
+
+	if idx < 0 {
+		a.name = strings.TrimSpace(line)
+		return a, nil
+	}
+
+	if !strings.HasSuffix(strings.TrimSpace(line), ")") {
+		return nil, fmt.Errorf("annotation @%s: missing closing parenthesis", line)
+	}
+
+	a.name = strings.TrimSpace(line[:idx])
+	body := strings.TrimSpace(line[idx+1:])
+	body = strings.TrimSuffix(body, ")")
+
+	for _, part := range splitArgs(body) {
+		part = strings.TrimSpace(part)
+
+		if part == "" {
+			continue
+		}
+
+		if key, value, ok := splitNamedArg(part); ok {
+			v, err := unquoteArg(value)
+
+			if err != nil {
+				return nil, fmt.Errorf("annotation @%s: %v", a.name, err)
+			}
+
+			a.named[key] = v
+			continue
+		}
+
+		v, err := unquoteArg(part)
+
+		if err != nil {
+			return nil, fmt.Errorf("annotation @%s: %v", a.name, err)
+		}
+
+		a.positional = append(a.positional, v)
+	}
+
+	return a, nil
+}
+
+// splitArgs splits on commas outside of quotes and braces
+func splitArgs(s1 string) []string {
+	parts := make([]string, 0)
+	var quote rune
+	var depth int
+	start := 0
+
+	for i, ch := range s1 {
+		switch {
+		case quote != 0:
+			if ch == quote && (i == 0 || s1[i-1] != '\\') {
+				quote = 0
+			}
+		case ch == '"' || ch == '`':
+			quote = ch
+		case ch == '{' || ch == '[':
+			depth++
+		case ch == '}' || ch == ']':
+			depth--
+		case ch == ',' && depth == 0:
+			parts = append(parts, s1[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s1[start:])
+}
+
+func splitNamedArg(part string) (key, value string, ok bool) {
+	if strings.HasPrefix(part, `"`) || strings.HasPrefix(part, "`") {
+		return "", "", false
+	}
+
+	idx := strings.Index(part, "=")
+
+	if idx <= 0 {
+		return "", "", false
+	}
+
+	key = strings.TrimSpace(part[:idx])
+
+	for _, ch := range key {
+		if !(ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9') {
+			return "", "", false
+		}
+	}
+
+	return key, strings.TrimSpace(part[idx+1:]), true
+}
+
+func unquoteArg(s1 string) (string, error) {
+	s1 = strings.TrimSpace(s1)
+
+	if strings.HasPrefix(s1, `"`) || strings.HasPrefix(s1, "`") {
+		return strconv.Unquote(s1)
+	}
+
+	return s1, nil
+}
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var mappingMethods = map[string]string{
+	"GetMapping":     "GET",
+	"PostMapping":    "POST",
+	"PutMapping":     "PUT",
+	"PatchMapping":   "PATCH",
+	"DeleteMapping":  "DELETE",
+	"RequestMapping": "",
+}
+
+type generator struct {
+	pkg       *packageInfo
+	funcName  string
+	buf       *bytes.Buffer
+	imports   map[string]string
+	usesTime  bool
+	usesFiber bool
+}
+
+func generate(pkg *packageInfo, funcName string) ([]byte, error) {
+	g := &generator{
+		pkg:      pkg,
+		funcName: funcName,
+		buf:      &bytes.Buffer{},
+		imports:  map[string]string{},
+	}
+
+	body, err := g.body()
+
+	if err != nil {
+		return nil, err
+	}
+
+	out := &bytes.Buffer{}
+	out.WriteString("// Code generated by mgboot-gen. DO NOT EDIT.\n\n")
+	out.WriteString("package " + pkg.name + "\n\n")
+	out.WriteString("import (\n")
+	paths := []string{strconv.Quote("github.com/meiguonet/mgboot-go-fiber/mgboot")}
+
+	if g.usesFiber {
+		paths = append(paths, strconv.Quote(fiberImportPath))
+	}
+
+	if g.usesTime {
+		paths = append(paths, strconv.Quote("time"))
+	}
+
+	for name, path := range g.imports {
+		paths = append(paths, name+" "+strconv.Quote(path))
+	}
+
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		out.WriteString("\t" + p + "\n")
+	}
+
+	out.WriteString(")\n\n")
+	out.Write(body)
+
+	src, err := format.Source(out.Bytes())
+
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %v\n%s", err, out.String())
+	}
+
+	return src, nil
+}
+
+func (g *generator) body() ([]byte, error) {
+	buf := g.buf
+	buf.WriteString(fmt.Sprintf("// %s registers the annotated handlers of package %s.\n", g.funcName, g.pkg.name))
+	buf.WriteString(fmt.Sprintf("func %s(router *mgboot.Router) {\n", g.funcName))
+
+	controllerNames := make([]string, 0, len(g.pkg.controllers))
+
+	for name := range g.pkg.controllers {
+		controllerNames = append(controllerNames, name)
+	}
+
+	sort.Strings(controllerNames)
+	routers := map[string]string{}
+
+	for _, name := range controllerNames {
+		c := g.pkg.controllers[name]
+
+		if !g.hasHandlers(name) {
+			continue
+		}
+
+		varName := lowerFirst(name)
+		buf.WriteString(fmt.Sprintf("\t%s := %s\n", varName, c.constructor))
+		prefix := ""
+
+		if a := findAnnotation(c.annotations, "RequestMapping"); a != nil {
+			prefix = convertPath(a.value("value", "path"))
+		}
+
+		policies, err := g.policies(c.annotations)
+
+		if err != nil {
+			return nil, fmt.Errorf("controller %s: %v", name, err)
+		}
+
+		if prefix == "" && len(policies) < 1 {
+			routers[name] = "router"
+			continue
+		}
+
+		groupVar := varName + "Router"
+		args := append([]string{strconv.Quote(prefix)}, policies...)
+		buf.WriteString(fmt.Sprintf("\t%s := router.Group(%s)\n", groupVar, strings.Join(args, ", ")))
+		routers[name] = groupVar
+	}
+
+	for _, h := range g.pkg.handlers {
+		routerVar := "router"
+		target := h.funcName
+		routeName := h.funcName
+
+		if h.receiver != "" {
+			routerVar = routers[h.receiver]
+			target = lowerFirst(h.receiver) + "." + h.funcName
+			routeName = h.receiver + "." + h.funcName
+		}
+
+		if a := findAnnotation(h.annotations, "Name"); a != nil && a.value("value") != "" {
+			routeName = a.value("value")
+		}
+
+		policies, err := g.policies(h.annotations)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", h.pos, err)
+		}
+
+		handler, err := g.handlerExpr(h, target)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", h.pos, err)
+		}
+
+		for _, a := range h.annotations {
+			method, ok := mappingMethods[a.name]
+
+			if !ok {
+				continue
+			}
+
+			methods := []string{method}
+
+			if method == "" {
+				methods = splitMethods(a.named["method"])
+			}
+
+			path := convertPath(a.value("value", "path"))
+
+			for _, m := range methods {
+				args := append([]string{strconv.Quote(m), strconv.Quote(path), handler}, policies...)
+				buf.WriteString(fmt.Sprintf("\t%s.Add(%s).SetName(%s)", routerVar, strings.Join(args, ", "), strconv.Quote(routeName)))
+				buf.WriteString(g.docCalls(h) + "\n")
+			}
+		}
+	}
+
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+// docCalls chains the openapi metadata of a handler onto its route
+func (g *generator) docCalls(h *handlerInfo) string {
+	sb := strings.Builder{}
+
+	if a := findAnnotation(h.annotations, "Summary"); a != nil && a.value("value") != "" {
+		sb.WriteString(fmt.Sprintf(".\n\t\tSetSummary(%s)", strconv.Quote(a.value("value"))))
+	}
+
+	if a := findAnnotation(h.annotations, "Description"); a != nil && a.value("value") != "" {
+		sb.WriteString(fmt.Sprintf(".\n\t\tSetDescription(%s)", strconv.Quote(a.value("value"))))
+	}
+
+	tags := make([]string, 0)
+
+	if a := findAnnotation(h.annotations, "Tags"); a != nil {
+		tags = a.values("value")
+	} else if c := g.pkg.controllers[h.receiver]; c != nil {
+		if a := findAnnotation(c.annotations, "Tags"); a != nil {
+			tags = a.values("value")
+		} else {
+			tags = []string{h.receiver}
+		}
+	}
+
+	if len(tags) > 0 {
+		quoted := make([]string, 0, len(tags))
+
+		for _, tag := range tags {
+			quoted = append(quoted, strconv.Quote(tag))
+		}
+
+		sb.WriteString(fmt.Sprintf(".\n\t\tSetTags(%s)", strings.Join(quoted, ", ")))
+	}
+
+	if findAnnotation(h.annotations, "Deprecated") != nil {
+		sb.WriteString(".\n\t\tSetDeprecated()")
+	}
+
+	if h.dtoType != "" {
+		sb.WriteString(fmt.Sprintf(".\n\t\tSetInput(&%s{})", h.dtoType))
+	}
+
+	return sb.String()
+}
+
+func (g *generator) hasHandlers(receiver string) bool {
+	for _, h := range g.pkg.handlers {
+		if h.receiver == receiver {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *generator) handlerExpr(h *handlerInfo, target string) (string, error) {
+	if h.dtoType == "" && !h.hasPayload {
+		return target, nil
+	}
+
+	g.usesFiber = true
+
+	for name, path := range h.dtoImports {
+		if path == "" {
+			return "", fmt.Errorf("cannot resolve the import path of package %s", name)
+		}
+
+		g.imports[name] = path
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("func(ctx *fiber.Ctx) error {\n")
+	call := target + "(ctx)"
+
+	if h.dtoType != "" {
+		sb.WriteString(fmt.Sprintf("input := &%s{}\n\n", h.dtoType))
+		sb.WriteString("if err := mgboot.Bind(ctx, input); err != nil {\n")
+		sb.WriteString("return mgboot.SendOutput(ctx, nil, err)\n")
+		sb.WriteString("}\n\n")
+		call = target + "(ctx, input)"
+	}
+
+	if h.hasPayload {
+		sb.WriteString(fmt.Sprintf("payload, err := %s\n", call))
+		sb.WriteString("return mgboot.SendOutput(ctx, mgboot.ToResponsePayload(payload), err)\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("return %s\n", call))
+	}
+
+	sb.WriteString("}")
+	return sb.String(), nil
+}
+
+func (g *generator) policies(annotations []*annotation) ([]string, error) {
+	policies := make([]string, 0)
+
+	for _, a := range annotations {
+		switch a.name {
+		case "Cors":
+			policies = append(policies, "mgboot.Cors()")
+		case "JwtAuth":
+			key := a.value("value", "settingsKey")
+
+			if key == "" {
+				return nil, fmt.Errorf("@JwtAuth requires a settings key")
+			}
+
+			policies = append(policies, fmt.Sprintf("mgboot.JwtAuth(%s)", strconv.Quote(key)))
+		case "PermitAll":
+			policies = append(policies, "mgboot.PermitAll()")
+		case "Profile":
+			name := a.value("value", "name")
+
+			if name == "" {
+				return nil, fmt.Errorf("@Profile requires a profile name")
+			}
+
+			policies = append(policies, fmt.Sprintf("mgboot.Profile(%s)", strconv.Quote(name)))
+		case "RateLimit":
+			total, err := strconv.Atoi(a.named["total"])
+
+			if err != nil || total < 1 {
+				return nil, fmt.Errorf("@RateLimit requires total=<positive int>")
+			}
+
+			d, err := time.ParseDuration(a.named["duration"])
+
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("@RateLimit requires duration=<duration like 1s>")
+			}
+
+			g.usesTime = true
+			args := []string{strconv.Itoa(total), durationExpr(d)}
+
+			if b, _ := strconv.ParseBool(a.named["limitByIp"]); b {
+				args = append(args, "true")
+			}
+
+			policies = append(policies, fmt.Sprintf("mgboot.RateLimit(%s)", strings.Join(args, ", ")))
+		case "Validate":
+			rules := make([]string, 0, len(a.positional))
+
+			for _, rule := range a.positional {
+				rules = append(rules, strconv.Quote(rule))
+			}
+
+			fn := "Validate"
+
+			if b, _ := strconv.ParseBool(a.named["failfast"]); b {
+				fn = "ValidateFailfast"
+			}
+
+			policies = append(policies, fmt.Sprintf("mgboot.%s(%s)", fn, strings.Join(rules, ", ")))
+		case "Timeout":
+			d, err := time.ParseDuration(a.value("value"))
+
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("@Timeout requires a duration like 5s")
+			}
+
+			g.usesTime = true
+			policies = append(policies, fmt.Sprintf("mgboot.Timeout(%s)", durationExpr(d)))
+		}
+	}
+
+	return policies, nil
+}
+
+// convertPath turns spring style /users/{id} into fiber style /users/:id
+func convertPath(path string) string {
+	sb := strings.Builder{}
+
+	for i := 0; i < len(path); i++ {
+		if path[i] != '{' {
+			sb.WriteByte(path[i])
+			continue
+		}
+
+		end := strings.IndexByte(path[i:], '}')
+
+		if end < 0 {
+			sb.WriteString(path[i:])
+			break
+		}
+
+		name := path[i+1 : i+end]
+
+		if idx := strings.Index(name, ":"); idx >= 0 {
+			name = name[:idx]
+		}
+
+		sb.WriteString(":" + name)
+		i += end
+	}
+
+	return sb.String()
+}
+
+func splitMethods(s1 string) []string {
+	methods := make([]string, 0)
+
+	for _, m := range strings.FieldsFunc(s1, func(r rune) bool { return r == '|' || r == ',' || r == ' ' }) {
+		methods = append(methods, strings.ToUpper(m))
+	}
+
+	if len(methods) < 1 {
+		methods = append(methods, "GET")
+	}
+
+	return methods
+}
+
+func durationExpr(d time.Duration) string {
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+	}
+
+	for _, u := range units {
+		if d%u.unit == 0 {
+			return fmt.Sprintf("%d*%s", d/u.unit, u.name)
+		}
+	}
+
+	return fmt.Sprintf("time.Duration(%d)", int64(d))
+}
+
+func lowerFirst(s1 string) string {
+	if s1 == "" {
+		return s1
+	}
+
+	return strings.ToLower(s1[:1]) + s1[1:]
+}
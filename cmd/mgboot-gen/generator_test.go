@@ -0,0 +1,51 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateCompiles(t *testing.T) {
+	// the package is created inside the module so that the generated code resolves mgboot,
+	// the underscore keeps it out of ./... while the test runs
+	dir, err := ioutil.TempDir(".", "_gen")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+	src, err := ioutil.ReadFile(filepath.Join("testdata", "controllers", "controllers.go"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "controllers.go"), src, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run(dir, "routes_gen.go", "RegisterRoutes"); err != nil {
+		t.Fatal(err)
+	}
+
+	generated, err := ioutil.ReadFile(filepath.Join(dir, "routes_gen.go"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(generated), "mgboot.ToResponsePayload(payload)") {
+		t.Fatalf("payload handlers are not wrapped:\n%s", generated)
+	}
+
+	out, err := exec.Command("go", "vet", "./"+filepath.ToSlash(dir)).CombinedOutput()
+
+	if err != nil {
+		t.Fatalf("generated code does not compile: %v\n%s\n%s", err, out, generated)
+	}
+}
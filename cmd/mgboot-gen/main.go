@@ -0,0 +1,63 @@
+// Command mgboot-gen scans a controller package for comment annotations and generates
+// the route registration for mgboot.Router, use it from a go:generate directive:
+//
+//	//go:generate go run github.com/meiguonet/mgboot-go-fiber/cmd/mgboot-gen -dir . -out routes_gen.go
+//
+// Supported annotations:
+//
+//	on controller types: @RestController, @RequestMapping("/prefix") and any policy annotation
+//	on handlers: @GetMapping("/users/{id}"), @PostMapping, @PutMapping, @PatchMapping, @DeleteMapping,
+//	@RequestMapping(value="/x", method="GET|POST"), @Name("user.get")
+//	policies: @Profile("admin"), @Cors, @JwtAuth("app"), @PermitAll, @RateLimit(total=10,duration=1s,limitByIp=true),
+//	@Validate("name@Required", "phone@Mobile", failfast=true), @Timeout("5s")
+//	openapi: @Summary("..."), @Description("..."), @Tags("user", "admin"), @Deprecated,
+//	@Tags on a controller applies to all of its handlers, the controller name is the default tag
+//
+// Handlers look like func(ctx *fiber.Ctx[, dto *Dto]) ([payload, ]error), a dto is filled by mgboot.Bind
+// and a payload is written by mgboot.SendOutput, a payload which is not a mgboot.ResponsePayload is sent
+// as mgboot.JsonResponse, the dto is also passed to Route.SetInput for the openapi document.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "controller package directory")
+	out := flag.String("out", "routes_gen.go", "generated file name, relative to dir")
+	funcName := flag.String("func", "RegisterRoutes", "name of the generated registration function")
+	flag.Parse()
+
+	if err := run(*dir, *out, *funcName); err != nil {
+		fmt.Fprintln(os.Stderr, "mgboot-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, out, funcName string) error {
+	if !filepath.IsAbs(out) {
+		out = filepath.Join(dir, out)
+	}
+
+	pkg, err := scanPackage(dir, out)
+
+	if err != nil {
+		return err
+	}
+
+	if len(pkg.handlers) < 1 {
+		return fmt.Errorf("no annotated handlers found in %s", dir)
+	}
+
+	src, err := generate(pkg, funcName)
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(out, src, 0644)
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const fiberImportPath = "github.com/gofiber/fiber/v2"
+
+type controllerInfo struct {
+	typeName    string
+	constructor string
+	annotations []*annotation
+}
+
+type handlerInfo struct {
+	pos         token.Position
+	receiver    string
+	funcName    string
+	annotations []*annotation
+	dtoType     string
+	dtoImports  map[string]string
+	hasPayload  bool
+}
+
+type packageInfo struct {
+	name        string
+	controllers map[string]*controllerInfo
+	handlers    []*handlerInfo
+}
+
+func scanPackage(dir, outFile string) (*packageInfo, error) {
+	fset := token.NewFileSet()
+
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		name := fi.Name()
+		return !strings.HasSuffix(name, "_test.go") && name != filepath.Base(outFile)
+	}, parser.ParseComments)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected exactly one package in %s, found %d", dir, len(pkgs))
+	}
+
+	info := &packageInfo{controllers: map[string]*controllerInfo{}}
+	var pkg *ast.Package
+
+	for name, p := range pkgs {
+		info.name = name
+		pkg = p
+	}
+
+	fileNames := make([]string, 0, len(pkg.Files))
+
+	for name := range pkg.Files {
+		fileNames = append(fileNames, name)
+	}
+
+	sort.Strings(fileNames)
+	constructors := map[string]bool{}
+
+	for _, name := range fileNames {
+		file := pkg.Files[name]
+
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				if err := scanTypeDecl(d, info); err != nil {
+					return nil, fmt.Errorf("%s: %v", fset.Position(d.Pos()), err)
+				}
+			case *ast.FuncDecl:
+				if d.Recv == nil && d.Type.Params.NumFields() == 0 && strings.HasPrefix(d.Name.Name, "New") {
+					constructors[d.Name.Name] = true
+				}
+
+				handler, err := scanFuncDecl(fset, file, d)
+
+				if err != nil {
+					return nil, fmt.Errorf("%s: %v", fset.Position(d.Pos()), err)
+				}
+
+				if handler != nil {
+					info.handlers = append(info.handlers, handler)
+				}
+			}
+		}
+	}
+
+	for _, c := range info.controllers {
+		if constructors["New"+c.typeName] {
+			c.constructor = "New" + c.typeName + "()"
+		} else {
+			c.constructor = "&" + c.typeName + "{}"
+		}
+	}
+
+	for _, h := range info.handlers {
+		if h.receiver != "" && info.controllers[h.receiver] == nil {
+			info.controllers[h.receiver] = &controllerInfo{
+				typeName:    h.receiver,
+				constructor: "&" + h.receiver + "{}",
+			}
+
+			if constructors["New"+h.receiver] {
+				info.controllers[h.receiver].constructor = "New" + h.receiver + "()"
+			}
+		}
+	}
+
+	return info, nil
+}
+
+func scanTypeDecl(d *ast.GenDecl, info *packageInfo) error {
+	if d.Tok != token.TYPE {
+		return nil
+	}
+
+	for _, spec := range d.Specs {
+		ts := spec.(*ast.TypeSpec)
+		doc := ts.Doc
+
+		if doc == nil && len(d.Specs) == 1 {
+			doc = d.Doc
+		}
+
+		if doc == nil {
+			continue
+		}
+
+		annotations, err := parseAnnotations(commentLines(doc))
+
+		if err != nil {
+			return err
+		}
+
+		if !hasAnnotation(annotations, "RestController", "Controller", "RequestMapping") {
+			continue
+		}
+
+		info.controllers[ts.Name.Name] = &controllerInfo{
+			typeName:    ts.Name.Name,
+			annotations: annotations,
+		}
+	}
+
+	return nil
+}
+
+func scanFuncDecl(fset *token.FileSet, file *ast.File, d *ast.FuncDecl) (*handlerInfo, error) {
+	if d.Doc == nil {
+		return nil, nil
+	}
+
+	annotations, err := parseAnnotations(commentLines(d.Doc))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if findRouteMapping(annotations) == nil {
+		return nil, nil
+	}
+
+	h := &handlerInfo{
+		pos:         fset.Position(d.Pos()),
+		funcName:    d.Name.Name,
+		annotations: annotations,
+		dtoImports:  map[string]string{},
+	}
+
+	if d.Recv != nil && len(d.Recv.List) > 0 {
+		recv := d.Recv.List[0].Type
+
+		if star, ok := recv.(*ast.StarExpr); ok {
+			recv = star.X
+		}
+
+		ident, ok := recv.(*ast.Ident)
+
+		if !ok {
+			return nil, fmt.Errorf("%s: unsupported receiver type", d.Name.Name)
+		}
+
+		h.receiver = ident.Name
+	}
+
+	fiberAlias := importAlias(file, fiberImportPath, "fiber")
+	params := flattenFields(d.Type.Params)
+
+	if len(params) < 1 || len(params) > 2 || !isSelectorPtr(params[0], fiberAlias, "Ctx") {
+		return nil, fmt.Errorf("%s: handler must be func(ctx *%s.Ctx[, dto *Dto]) ([payload, ]error)", d.Name.Name, fiberAlias)
+	}
+
+	if len(params) == 2 {
+		star, ok := params[1].(*ast.StarExpr)
+
+		if !ok {
+			return nil, fmt.Errorf("%s: dto parameter must be a pointer to struct", d.Name.Name)
+		}
+
+		h.dtoType = exprString(fset, star.X)
+
+		if sel, ok := star.X.(*ast.SelectorExpr); ok {
+			if pkgIdent, ok := sel.X.(*ast.Ident); ok {
+				h.dtoImports[pkgIdent.Name] = importPathOf(file, pkgIdent.Name)
+			}
+		}
+	}
+
+	results := flattenFields(d.Type.Results)
+
+	switch len(results) {
+	case 1:
+		if !isIdent(results[0], "error") {
+			return nil, fmt.Errorf("%s: single result must be error", d.Name.Name)
+		}
+	case 2:
+		if !isIdent(results[1], "error") {
+			return nil, fmt.Errorf("%s: second result must be error", d.Name.Name)
+		}
+
+		h.hasPayload = true
+	default:
+		return nil, fmt.Errorf("%s: handler must return error or (payload, error)", d.Name.Name)
+	}
+
+	return h, nil
+}
+
+func commentLines(doc *ast.CommentGroup) []string {
+	lines := make([]string, 0, len(doc.List))
+
+	for _, c := range doc.List {
+		for _, line := range strings.Split(c.Text, "\n") {
+			lines = append(lines, strings.TrimSuffix(strings.TrimSpace(line), "*/"))
+		}
+	}
+
+	return lines
+}
+
+func hasAnnotation(annotations []*annotation, names ...string) bool {
+	for _, a := range annotations {
+		for _, name := range names {
+			if a.name == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func findAnnotation(annotations []*annotation, name string) *annotation {
+	for _, a := range annotations {
+		if a.name == name {
+			return a
+		}
+	}
+
+	return nil
+}
+
+func findRouteMapping(annotations []*annotation) *annotation {
+	for _, a := range annotations {
+		if _, ok := mappingMethods[a.name]; ok {
+			return a
+		}
+	}
+
+	return nil
+}
+
+func flattenFields(fl *ast.FieldList) []ast.Expr {
+	exprs := make([]ast.Expr, 0)
+
+	if fl == nil {
+		return exprs
+	}
+
+	for _, f := range fl.List {
+		n := len(f.Names)
+
+		if n == 0 {
+			n = 1
+		}
+
+		for i := 0; i < n; i++ {
+			exprs = append(exprs, f.Type)
+		}
+	}
+
+	return exprs
+}
+
+func isSelectorPtr(expr ast.Expr, pkgName, typeName string) bool {
+	star, ok := expr.(*ast.StarExpr)
+
+	if !ok {
+		return false
+	}
+
+	sel, ok := star.X.(*ast.SelectorExpr)
+
+	if !ok {
+		return false
+	}
+
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == pkgName && sel.Sel.Name == typeName
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+func importAlias(file *ast.File, path, defaultName string) string {
+	for _, spec := range file.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+
+		if p != path {
+			continue
+		}
+
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+
+		return defaultName
+	}
+
+	return defaultName
+}
+
+func importPathOf(file *ast.File, name string) string {
+	for _, spec := range file.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+
+		if spec.Name != nil && spec.Name.Name == name {
+			return p
+		}
+
+		if spec.Name == nil && filepath.Base(p) == name {
+			return p
+		}
+	}
+
+	return ""
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	buf := &bytes.Buffer{}
+	printer.Fprint(buf, fset, expr)
+	return buf.String()
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-fiber/mgboot"
+)
+
+type User struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type UserDto struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// @RestController
+// @RequestMapping("/users")
+type UserController struct {
+}
+
+func NewUserController() *UserController {
+	return &UserController{}
+}
+
+// @GetMapping("/{id}")
+func (c *UserController) Get(ctx *fiber.Ctx) (*User, error) {
+	return &User{Id: 1, Name: "test"}, nil
+}
+
+// @GetMapping("")
+func (c *UserController) List(ctx *fiber.Ctx) ([]User, error) {
+	return []User{}, nil
+}
+
+// @PostMapping("")
+// @Summary("create user")
+func (c *UserController) Create(ctx *fiber.Ctx, dto *UserDto) (map[string]interface{}, error) {
+	return map[string]interface{}{"name": dto.Name}, nil
+}
+
+// @PutMapping("/{id}")
+func (c *UserController) Update(ctx *fiber.Ctx, dto *UserDto) (mgboot.ResponsePayload, error) {
+	return mgboot.NewJsonResponse(dto), nil
+}
+
+// @DeleteMapping("/{id}")
+func (c *UserController) Delete(ctx *fiber.Ctx) error {
+	return ctx.SendString("")
+}
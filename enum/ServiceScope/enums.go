@@ -0,0 +1,7 @@
+package ServiceScope
+
+const (
+	Singleton = iota + 1
+	Request
+	Transient
+)
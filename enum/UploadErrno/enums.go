@@ -0,0 +1,15 @@
+package UploadErrno
+
+const (
+	NoFile = -1
+	FileTooLarge = -2
+	FileTooSmall = -3
+	ExtensionNotAllowed = -4
+	MimeTypeNotAllowed = -5
+	MimeTypeMismatch = -6
+	InvalidImage = -7
+	ImageSizeMismatch = -8
+	ImageTooSmall = -9
+	ImageTooLarge = -10
+	ImageRatioMismatch = -11
+)
@@ -1,17 +1,29 @@
 module github.com/meiguonet/mgboot-go-fiber
 
-go 1.15
+go 1.16
 
 require (
-	github.com/aliyun/alibaba-cloud-sdk-go v1.61.1311 // indirect
-	github.com/aliyun/aliyun-log-go-sdk v0.1.22 // indirect
-	github.com/dgrijalva/jwt-go v3.2.0+incompatible // indirect
-	github.com/gofiber/fiber/v2 v2.21.0 // indirect
-	github.com/gogo/protobuf v1.3.2 // indirect
+	github.com/aliyun/aliyun-log-go-sdk v0.1.22
+	github.com/dgrijalva/jwt-go v3.2.0+incompatible
+	github.com/go-errors/errors v1.4.1
+	github.com/gofiber/fiber/v2 v2.21.0
+	github.com/gogo/protobuf v1.3.2
+	github.com/gomodule/redigo v1.8.5
+	github.com/klauspost/compress v1.13.4
 	github.com/meiguonet/mgboot-go-common v1.1.0
-	github.com/meiguonet/mgboot-go-dal v1.0.6 // indirect
+	github.com/meiguonet/mgboot-go-dal v1.0.6
 	github.com/patrickmn/go-cache v2.1.0+incompatible
-	github.com/robfig/cron/v3 v3.0.1 // indirect
-	github.com/sirupsen/logrus v1.8.1 // indirect
+	github.com/prometheus/client_golang v1.11.0
+	github.com/robfig/cron/v3 v3.0.1
+	github.com/sirupsen/logrus v1.8.1
+	github.com/valyala/fasthttp v1.31.0
 	github.com/vmihailenco/msgpack/v5 v5.3.5
+	go.opentelemetry.io/otel v1.3.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.3.0
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.3.0
+	go.opentelemetry.io/otel/sdk v1.3.0
+	go.opentelemetry.io/otel/trace v1.3.0
+	golang.org/x/image v0.0.0-20211028202545-6944b10bf410
+	golang.org/x/net v0.0.0-20210510120150-4163338589ed
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
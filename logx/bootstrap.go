@@ -0,0 +1,78 @@
+package logx
+
+import (
+	"context"
+	"errors"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/fsx"
+	"github.com/meiguonet/mgboot-go-fiber/mgboot"
+	"strings"
+)
+
+// LogDir returns the dir of the file appenders which do not set filepath
+func LogDir() string {
+	dir := logDir
+
+	if dir == "" {
+		dir = fsx.GetRealpath("datadir:logs")
+	}
+
+	dir = strings.ReplaceAll(dir, "\\", "/")
+	return strings.TrimRight(dir, "/")
+}
+
+// Flush waits for the entries which are still being written by the appenders, the entries logged
+// meanwhile are still accepted so that logging keeps working after the shutdown
+func Flush(ctx context.Context) error {
+	done := make(chan struct{})
+
+	go func() {
+		waitPendingWrites()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.New("in logx.Flush, timeout waiting for the appenders")
+	}
+}
+
+// AppComponent boots the loggers from logging.logDir, alysls and logging.loggers in AppConf,
+// the channels runtime, request, executeTime and accessLog are set as the loggers of mgboot if defined,
+// the logDir health check is registered and the appenders are flushed on shutdown
+func AppComponent() mgboot.AppComponent {
+	boot := func(_ *mgboot.Application) error {
+		if dir := AppConf.GetString("logging.logDir"); dir != "" {
+			WithLogDir(dir)
+		}
+
+		if len(AppConf.GetMap("alysls")) > 0 {
+			WithAlyslsSettings()
+		}
+
+		InitLoggers()
+
+		if logger, ok := loggers["runtime"]; ok {
+			mgboot.RuntimeLogger(logger)
+		}
+
+		if logger, ok := loggers["request"]; ok {
+			mgboot.RequestLogLogger(logger)
+		}
+
+		if logger, ok := loggers["executeTime"]; ok {
+			mgboot.ExecuteTimeLogLogger(logger)
+		}
+
+		if logger, ok := loggers["accessLog"]; ok {
+			mgboot.AccessLogLogger(logger)
+		}
+
+		mgboot.WithHealthCheck("logDir", mgboot.DirWritableHealthCheck(LogDir))
+		return nil
+	}
+
+	return mgboot.NewAppComponent("logging", mgboot.AppOrderLogging, boot, Flush)
+}
@@ -1,6 +1,14 @@
 package logx
 
-import "sync"
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// pendingWrites counts the entries being written by the appenders
+var pendingWrites int64
+var pendingMu = &sync.Mutex{}
+var pendingCond = sync.NewCond(pendingMu)
 
 type writer struct {
 	appenders []appender
@@ -11,19 +19,39 @@ func (w *writer) Write(buf []byte) (int, error) {
 		return len(buf), nil
 	}
 
+	atomic.AddInt64(&pendingWrites, int64(len(w.appenders)))
+
 	if len(w.appenders) == 1 {
+		defer endWrite()
 		return w.appenders[0].Write(buf)
 	}
 
-	wg := &sync.WaitGroup{}
-	wg.Add(len(w.appenders))
-
 	for _, a := range w.appenders {
 		go func(a appender) {
-			defer wg.Done()
+			defer endWrite()
 			_, _ = a.Write(buf)
 		}(a)
 	}
 
 	return len(buf), nil
 }
+
+func endWrite() {
+	if atomic.AddInt64(&pendingWrites, -1) > 0 {
+		return
+	}
+
+	pendingMu.Lock()
+	pendingCond.Broadcast()
+	pendingMu.Unlock()
+}
+
+func waitPendingWrites() {
+	pendingMu.Lock()
+
+	for atomic.LoadInt64(&pendingWrites) > 0 {
+		pendingCond.Wait()
+	}
+
+	pendingMu.Unlock()
+}
@@ -0,0 +1,27 @@
+package mgboot
+
+import "fmt"
+
+type AccessDeniedError struct {
+	clientIp    string
+	ruleSetName string
+}
+
+func NewAccessDeniedError(clientIp, ruleSetName string) AccessDeniedError {
+	return AccessDeniedError{
+		clientIp:    clientIp,
+		ruleSetName: ruleSetName,
+	}
+}
+
+func (ex AccessDeniedError) Error() string {
+	return fmt.Sprintf("access denied for %s by ip filter rules: %s", ex.clientIp, ex.ruleSetName)
+}
+
+func (ex AccessDeniedError) ClientIp() string {
+	return ex.clientIp
+}
+
+func (ex AccessDeniedError) RuleSetName() string {
+	return ex.ruleSetName
+}
@@ -0,0 +1,26 @@
+package mgboot
+
+import "github.com/gofiber/fiber/v2"
+
+type accessDeniedErrorHandler struct {
+}
+
+func NewAccessDeniedErrorHandler() *accessDeniedErrorHandler {
+	return &accessDeniedErrorHandler{}
+}
+
+func (h *accessDeniedErrorHandler) GetErrorName() string {
+	return "builtin.AccessDeniedError"
+}
+
+func (h *accessDeniedErrorHandler) MatchError(err error) bool {
+	if _, ok := err.(AccessDeniedError); ok {
+		return true
+	}
+
+	return false
+}
+
+func (h *accessDeniedErrorHandler) HandleError(_ error) ResponsePayload {
+	return NewHttpErrorResponse(fiber.StatusForbidden)
+}
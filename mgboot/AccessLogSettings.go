@@ -0,0 +1,93 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"strings"
+)
+
+type AccessLogSettings struct {
+	format       string
+	template     string
+	subjectClaim string
+	skipPaths    []string
+}
+
+// NewAccessLogSettings supported keys: format (combined|json|template), template, subjectClaim, skipPaths,
+// a format other than combined and json is treated as the template itself
+func NewAccessLogSettings(settings map[string]interface{}) *AccessLogSettings {
+	format := "combined"
+	var template string
+
+	if s1 := strings.TrimSpace(castx.ToString(settings["format"])); s1 != "" {
+		switch strings.ToLower(s1) {
+		case "combined", "json", "template":
+			format = strings.ToLower(s1)
+		default:
+			format = "template"
+			template = s1
+		}
+	}
+
+	if s1 := castx.ToString(settings["template"]); s1 != "" {
+		template = s1
+	}
+
+	if format == "template" && template == "" {
+		format = "combined"
+	}
+
+	subjectClaim := "sub"
+
+	if s1 := castx.ToString(settings["subjectClaim"]); s1 != "" {
+		subjectClaim = s1
+	}
+
+	skipPaths := make([]string, 0)
+
+	for _, s1 := range castx.ToStringSlice(settings["skipPaths"]) {
+		if s1 = strings.TrimSpace(s1); s1 != "" {
+			skipPaths = append(skipPaths, s1)
+		}
+	}
+
+	return &AccessLogSettings{
+		format:       format,
+		template:     template,
+		subjectClaim: subjectClaim,
+		skipPaths:    skipPaths,
+	}
+}
+
+func (st *AccessLogSettings) Format() string {
+	return st.format
+}
+
+func (st *AccessLogSettings) Template() string {
+	return st.template
+}
+
+func (st *AccessLogSettings) SubjectClaim() string {
+	return st.subjectClaim
+}
+
+func (st *AccessLogSettings) SkipPaths() []string {
+	return st.skipPaths
+}
+
+func (st *AccessLogSettings) IsSkipped(path string) bool {
+	for _, s1 := range st.skipPaths {
+		if strings.HasSuffix(s1, "*") {
+			if strings.HasPrefix(path, strings.TrimSuffix(s1, "*")) {
+				return true
+			}
+
+			continue
+		}
+
+		if path == s1 {
+			return true
+		}
+	}
+
+	return false
+}
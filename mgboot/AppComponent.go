@@ -0,0 +1,59 @@
+package mgboot
+
+import "context"
+
+// AppComponent is booted by Application in ascending order of ComponentOrder and shut down in the reverse order,
+// only the components which have been booted successfully are shut down
+type AppComponent interface {
+	ComponentName() string
+	ComponentOrder() int
+	Boot(app *Application) error
+	Shutdown(ctx context.Context) error
+}
+
+type funcComponent struct {
+	name     string
+	order    int
+	boot     func(app *Application) error
+	shutdown func(ctx context.Context) error
+}
+
+// NewAppComponent builds an AppComponent from functions, boot and shutdown may be nil
+func NewAppComponent(
+	name string,
+	order int,
+	boot func(app *Application) error,
+	shutdown ...func(ctx context.Context) error,
+) AppComponent {
+	c := &funcComponent{name: name, order: order, boot: boot}
+
+	if len(shutdown) > 0 {
+		c.shutdown = shutdown[0]
+	}
+
+	return c
+}
+
+func (c *funcComponent) ComponentName() string {
+	return c.name
+}
+
+func (c *funcComponent) ComponentOrder() int {
+	return c.order
+}
+
+func (c *funcComponent) Boot(app *Application) error {
+	if c.boot == nil {
+		return nil
+	}
+
+	return c.boot(app)
+}
+
+func (c *funcComponent) Shutdown(ctx context.Context) error {
+	if c.shutdown == nil {
+		return nil
+	}
+
+	return c.shutdown(ctx)
+}
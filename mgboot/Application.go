@@ -0,0 +1,406 @@
+package mgboot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type startupCheck struct {
+	name    string
+	check   func(ctx context.Context) error
+	timeout time.Duration
+}
+
+// Application boots the components in a defined order, serves http until SIGINT/SIGTERM or Shutdown is called,
+// and then shuts down gracefully: the http server is drained first and the components are shut down in the reverse order
+type Application struct {
+	fiber           *fiber.App
+	router          *Router
+	components      []AppComponent
+	booted          []AppComponent
+	checks          []startupCheck
+	beforeStart     []AppHook
+	afterStart      []AppHook
+	beforeStop      []AppHook
+	afterStop       []AppHook
+	shutdownTimeout time.Duration
+	mu              sync.Mutex
+	stopCh          chan struct{}
+	stopOnce        sync.Once
+}
+
+// NewApplication builds the fiber app with DefaultErrorHandler unless config is given, the builtin components
+// (settings, pools, cache, container) are registered, logx.AppComponent and taskx.AppComponent are added by WithComponents
+func NewApplication(config ...fiber.Config) *Application {
+	var cfg fiber.Config
+
+	if len(config) > 0 {
+		cfg = config[0]
+	} else {
+		cfg = fiber.Config{ErrorHandler: DefaultErrorHandler()}
+	}
+
+	app := fiber.New(cfg)
+
+	return &Application{
+		fiber:  app,
+		router: NewRouter(app),
+		components: []AppComponent{
+			settingsComponent(),
+			poolsComponent(),
+			cacheComponent(),
+			containerComponent(),
+		},
+		booted:      make([]AppComponent, 0),
+		checks:      make([]startupCheck, 0),
+		beforeStart: make([]AppHook, 0),
+		afterStart:  make([]AppHook, 0),
+		beforeStop:  make([]AppHook, 0),
+		afterStop:   make([]AppHook, 0),
+		stopCh:      make(chan struct{}),
+	}
+}
+
+func (app *Application) Fiber() *fiber.App {
+	return app.fiber
+}
+
+func (app *Application) Router() *Router {
+	return app.router
+}
+
+func (app *Application) Container() *Container {
+	return DefaultContainer()
+}
+
+// WithConfigFile loads AppConf from a yaml or json file before any other component is booted
+func (app *Application) WithConfigFile(fpath string) *Application {
+	return app.WithComponents(configComponent(fpath))
+}
+
+// WithComponents adds components, a builtin component is replaced by the one which has the same name
+func (app *Application) WithComponents(components ...AppComponent) *Application {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	for _, c := range components {
+		if c == nil {
+			continue
+		}
+
+		var replaced bool
+
+		for i, c1 := range app.components {
+			if c1.ComponentName() == c.ComponentName() {
+				app.components[i] = c
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			app.components = append(app.components, c)
+		}
+	}
+
+	return app
+}
+
+// WithStartupCheck adds a check run after all of the components are booted and before http is served,
+// the application does not start when any of the checks fails, the default timeout is 5s
+func (app *Application) WithStartupCheck(name string, check func(ctx context.Context) error, timeout ...time.Duration) *Application {
+	_timeout := 5 * time.Second
+
+	if len(timeout) > 0 && timeout[0] > 0 {
+		_timeout = timeout[0]
+	}
+
+	app.mu.Lock()
+	app.checks = append(app.checks, startupCheck{name: name, check: check, timeout: _timeout})
+	app.mu.Unlock()
+	return app
+}
+
+// BeforeStart hooks run before the components are booted, an error aborts the start
+func (app *Application) BeforeStart(hook AppHook) *Application {
+	app.mu.Lock()
+	app.beforeStart = append(app.beforeStart, hook)
+	app.mu.Unlock()
+	return app
+}
+
+// AfterStart hooks run once http is served
+func (app *Application) AfterStart(hook AppHook) *Application {
+	app.mu.Lock()
+	app.afterStart = append(app.afterStart, hook)
+	app.mu.Unlock()
+	return app
+}
+
+// BeforeStop hooks run before http is drained
+func (app *Application) BeforeStop(hook AppHook) *Application {
+	app.mu.Lock()
+	app.beforeStop = append(app.beforeStop, hook)
+	app.mu.Unlock()
+	return app
+}
+
+// AfterStop hooks run once all of the components are shut down
+func (app *Application) AfterStop(hook AppHook) *Application {
+	app.mu.Lock()
+	app.afterStop = append(app.afterStop, hook)
+	app.mu.Unlock()
+	return app
+}
+
+// ShutdownTimeout limits the whole graceful shutdown, the default is server.shutdownTimeout in AppConf or 30s
+func (app *Application) ShutdownTimeout(timeout ...time.Duration) time.Duration {
+	if len(timeout) > 0 && timeout[0] > 0 {
+		app.shutdownTimeout = timeout[0]
+	}
+
+	if app.shutdownTimeout > 0 {
+		return app.shutdownTimeout
+	}
+
+	if d1 := AppConf.GetDuration("server.shutdownTimeout"); d1 > 0 {
+		return d1
+	}
+
+	return 30 * time.Second
+}
+
+// Run boots the application and blocks until it is shut down, the default addr is server.addr in AppConf,
+// or :server.port, or :8080
+func (app *Application) Run(addr ...string) error {
+	ctx := context.Background()
+
+	if err := app.runHooks(ctx, "BeforeStart", app.beforeStart); err != nil {
+		return err
+	}
+
+	if err := app.boot(); err != nil {
+		app.shutdownComponents()
+		return err
+	}
+
+	if err := ValidateRouteProfiles(app.router); err != nil {
+		app.shutdownComponents()
+		return err
+	}
+
+	if err := app.runStartupChecks(ctx); err != nil {
+		app.shutdownComponents()
+		return err
+	}
+
+	_addr := app.listenAddr(addr)
+	listenErr := make(chan error, 1)
+
+	go func() {
+		listenErr <- app.fiber.Listen(_addr)
+	}()
+
+	RuntimeLogger().Info("application started, listen on " + _addr)
+
+	if err := app.runHooks(ctx, "AfterStart", app.afterStart); err != nil {
+		RuntimeLogger().Error(err.Error())
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	var serveErr error
+
+	select {
+	case sig := <-signals:
+		RuntimeLogger().Info("application receive signal: " + sig.String())
+	case <-app.stopCh:
+	case serveErr = <-listenErr:
+		if serveErr != nil {
+			RuntimeLogger().Error("in mgboot.Application, http server stopped: " + serveErr.Error())
+		}
+	}
+
+	err := app.shutdown()
+
+	if serveErr != nil {
+		return serveErr
+	}
+
+	return err
+}
+
+// Shutdown asks Run to shut down the application gracefully
+func (app *Application) Shutdown() {
+	app.stopOnce.Do(func() {
+		close(app.stopCh)
+	})
+}
+
+func (app *Application) boot() error {
+	app.mu.Lock()
+	components := make([]AppComponent, len(app.components))
+	copy(components, app.components)
+	app.mu.Unlock()
+
+	sort.SliceStable(components, func(i, j int) bool {
+		return components[i].ComponentOrder() < components[j].ComponentOrder()
+	})
+
+	for _, c := range components {
+		if err := c.Boot(app); err != nil {
+			return fmt.Errorf("in mgboot.Application, fail to boot component %s: %w", c.ComponentName(), err)
+		}
+
+		app.booted = append(app.booted, c)
+	}
+
+	return nil
+}
+
+func (app *Application) runStartupChecks(ctx context.Context) error {
+	messages := make([]string, 0)
+
+	for _, sc := range app.checks {
+		if err := runCheck(ctx, sc.check, sc.timeout); err != nil {
+			messages = append(messages, fmt.Sprintf("%s: %v", sc.name, err))
+		}
+	}
+
+	if len(messages) > 0 {
+		return errors.New("in mgboot.Application, startup check failed: " + strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+func (app *Application) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), app.ShutdownTimeout())
+	defer cancel()
+	messages := make([]string, 0)
+
+	if err := app.runHooks(ctx, "BeforeStop", app.beforeStop); err != nil {
+		messages = append(messages, err.Error())
+	}
+
+	drained := make(chan error, 1)
+
+	go func() {
+		drained <- app.fiber.Shutdown()
+	}()
+
+	select {
+	case err := <-drained:
+		if err != nil {
+			messages = append(messages, "http: "+err.Error())
+		}
+	case <-ctx.Done():
+		messages = append(messages, "http: timeout waiting for the running requests")
+	}
+
+	if err := app.shutdownComponentsWithContext(ctx); err != nil {
+		messages = append(messages, err.Error())
+	}
+
+	if err := app.runHooks(ctx, "AfterStop", app.afterStop); err != nil {
+		messages = append(messages, err.Error())
+	}
+
+	if len(messages) > 0 {
+		return errors.New("in mgboot.Application, shutdown failed: " + strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+func (app *Application) shutdownComponents() {
+	ctx, cancel := context.WithTimeout(context.Background(), app.ShutdownTimeout())
+	defer cancel()
+
+	if err := app.shutdownComponentsWithContext(ctx); err != nil {
+		RuntimeLogger().Error(err.Error())
+	}
+}
+
+func (app *Application) shutdownComponentsWithContext(ctx context.Context) error {
+	booted := app.booted
+	app.booted = make([]AppComponent, 0)
+	messages := make([]string, 0)
+
+	for i := len(booted) - 1; i >= 0; i-- {
+		if err := booted[i].Shutdown(ctx); err != nil {
+			messages = append(messages, fmt.Sprintf("%s: %v", booted[i].ComponentName(), err))
+		}
+	}
+
+	if len(messages) > 0 {
+		return errors.New("fail to shutdown components: " + strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+func (app *Application) runHooks(ctx context.Context, stage string, hooks []AppHook) error {
+	app.mu.Lock()
+	_hooks := make([]AppHook, len(hooks))
+	copy(_hooks, hooks)
+	app.mu.Unlock()
+
+	for _, hook := range _hooks {
+		if err := hook(ctx, app); err != nil {
+			return fmt.Errorf("in mgboot.Application, %s hook failed: %w", stage, err)
+		}
+	}
+
+	return nil
+}
+
+func (app *Application) listenAddr(addr []string) string {
+	if len(addr) > 0 && addr[0] != "" {
+		return addr[0]
+	}
+
+	if s1 := AppConf.GetString("server.addr"); s1 != "" {
+		return s1
+	}
+
+	if port := AppConf.GetInt("server.port"); port > 0 {
+		return fmt.Sprintf(":%d", port)
+	}
+
+	return ":8080"
+}
+
+func runCheck(ctx context.Context, check func(ctx context.Context) error, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+
+		result <- check(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timeout after %s", timeout)
+	}
+}
@@ -0,0 +1,27 @@
+package mgboot
+
+import "fmt"
+
+type BodyLimitError struct {
+	limitName string
+	limit     int64
+}
+
+func NewBodyLimitError(limitName string, limit int64) BodyLimitError {
+	return BodyLimitError{
+		limitName: limitName,
+		limit:     limit,
+	}
+}
+
+func (ex BodyLimitError) Error() string {
+	return fmt.Sprintf("request body exceeds limit: %s=%d", ex.limitName, ex.limit)
+}
+
+func (ex BodyLimitError) LimitName() string {
+	return ex.limitName
+}
+
+func (ex BodyLimitError) Limit() int64 {
+	return ex.limit
+}
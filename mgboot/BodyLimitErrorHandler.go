@@ -0,0 +1,26 @@
+package mgboot
+
+import "github.com/gofiber/fiber/v2"
+
+type bodyLimitErrorHandler struct {
+}
+
+func NewBodyLimitErrorHandler() *bodyLimitErrorHandler {
+	return &bodyLimitErrorHandler{}
+}
+
+func (h *bodyLimitErrorHandler) GetErrorName() string {
+	return "builtin.BodyLimitError"
+}
+
+func (h *bodyLimitErrorHandler) MatchError(err error) bool {
+	if _, ok := err.(BodyLimitError); ok {
+		return true
+	}
+
+	return false
+}
+
+func (h *bodyLimitErrorHandler) HandleError(_ error) ResponsePayload {
+	return NewHttpErrorResponse(fiber.StatusRequestEntityTooLarge)
+}
@@ -0,0 +1,136 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"strings"
+)
+
+type CompressSettings struct {
+	etagEnabled     bool
+	weakEtag        bool
+	compressEnabled bool
+	minSize         int64
+	level           int
+	encodings       []string
+	contentTypes    []string
+}
+
+func NewCompressSettings(settings map[string]interface{}) *CompressSettings {
+	etagEnabled := true
+
+	if b1, err := castx.ToBoolE(settings["etag"]); err == nil {
+		etagEnabled = b1
+	}
+
+	compressEnabled := true
+
+	if b1, err := castx.ToBoolE(settings["compress"]); err == nil {
+		compressEnabled = b1
+	}
+
+	minSize := int64(1024)
+
+	if n1, ok := settings["minSize"].(int64); ok && n1 >= 0 {
+		minSize = n1
+	} else if n1, ok := settings["minSize"].(int); ok && n1 >= 0 {
+		minSize = int64(n1)
+	} else if s1, ok := settings["minSize"].(string); ok && s1 != "" {
+		minSize = castx.ToDataSize(s1)
+	}
+
+	level := 6
+
+	if n1, err := castx.ToIntE(settings["level"]); err == nil && n1 > 0 && n1 <= 11 {
+		level = n1
+	}
+
+	encodings := []string{"br", "zstd", "gzip"}
+
+	if a1 := castx.ToStringSlice(settings["encodings"]); len(a1) > 0 {
+		encodings = make([]string, 0, len(a1))
+
+		for _, s1 := range a1 {
+			s1 = strings.ToLower(strings.TrimSpace(s1))
+
+			switch s1 {
+			case "br", "zstd", "gzip":
+				encodings = append(encodings, s1)
+			}
+		}
+	}
+
+	contentTypes := []string{
+		"application/json",
+		"text/html",
+		"text/xml",
+		"application/xml",
+	}
+
+	if a1 := castx.ToStringSlice(settings["contentTypes"]); len(a1) > 0 {
+		contentTypes = make([]string, 0, len(a1))
+
+		for _, s1 := range a1 {
+			if s1 = strings.ToLower(strings.TrimSpace(s1)); s1 != "" {
+				contentTypes = append(contentTypes, s1)
+			}
+		}
+	}
+
+	return &CompressSettings{
+		etagEnabled:     etagEnabled,
+		weakEtag:        castx.ToBool(settings["weakEtag"]),
+		compressEnabled: compressEnabled,
+		minSize:         minSize,
+		level:           level,
+		encodings:       encodings,
+		contentTypes:    contentTypes,
+	}
+}
+
+func (st *CompressSettings) EtagEnabled() bool {
+	return st.etagEnabled
+}
+
+func (st *CompressSettings) WeakEtag() bool {
+	return st.weakEtag
+}
+
+func (st *CompressSettings) CompressEnabled() bool {
+	return st.compressEnabled
+}
+
+func (st *CompressSettings) MinSize() int64 {
+	return st.minSize
+}
+
+func (st *CompressSettings) Level() int {
+	return st.level
+}
+
+func (st *CompressSettings) Encodings() []string {
+	return st.encodings
+}
+
+func (st *CompressSettings) ContentTypes() []string {
+	return st.contentTypes
+}
+
+func (st *CompressSettings) IsCompressible(contentType string) bool {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+
+	if strings.Contains(contentType, ";") {
+		contentType = strings.TrimSpace(contentType[:strings.Index(contentType, ";")])
+	}
+
+	for _, s1 := range st.contentTypes {
+		if s1 == contentType {
+			return true
+		}
+
+		if strings.HasSuffix(s1, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(s1, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,670 @@
+package mgboot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-fiber/enum/ServiceScope"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+var fiberCtxType = reflect.TypeOf(&fiber.Ctx{})
+
+type serviceKey struct {
+	typ  reflect.Type
+	name string
+}
+
+func (k serviceKey) String() string {
+	if k.name == "" {
+		return k.typ.String()
+	}
+
+	return k.typ.String() + "(" + k.name + ")"
+}
+
+type serviceProvider struct {
+	key        serviceKey
+	scope      int
+	ctor       reflect.Value
+	deps       []reflect.Type
+	returnsErr bool
+	target     *serviceKey
+	mu         sync.Mutex
+	created    bool
+	instance   reflect.Value
+}
+
+type requestScope struct {
+	mu        sync.Mutex
+	instances map[serviceKey]reflect.Value
+	order     []reflect.Value
+}
+
+type Container struct {
+	mu        sync.RWMutex
+	providers map[serviceKey]*serviceProvider
+	order     []serviceKey
+	created   []*serviceProvider
+	started   []*serviceProvider
+}
+
+func NewContainer() *Container {
+	return &Container{providers: map[serviceKey]*serviceProvider{}}
+}
+
+// Provide registers constructor as the provider of its first result type, the constructor looks like
+// func(deps ...) T or func(deps ...) (T, error), scope defaults to ServiceScope.Singleton
+func (c *Container) Provide(constructor interface{}, scope ...int) error {
+	return c.ProvideNamed("", constructor, scope...)
+}
+
+func (c *Container) ProvideNamed(name string, constructor interface{}, scope ...int) error {
+	return c.provide(name, constructor, scope...)
+}
+
+// Instance registers an existing value as a singleton of its dynamic type, use Bind to expose it as an interface
+func (c *Container) Instance(value interface{}, name ...string) error {
+	if value == nil {
+		return errors.New("in mgboot.Container.Instance, value must not be nil")
+	}
+
+	rv := reflect.ValueOf(value)
+	p := &serviceProvider{
+		key:      serviceKey{typ: rv.Type(), name: firstServiceName(name)},
+		scope:    ServiceScope.Singleton,
+		created:  true,
+		instance: rv,
+	}
+
+	c.register(p)
+	c.mu.Lock()
+	c.created = append(c.created, p)
+	c.mu.Unlock()
+	return nil
+}
+
+// Bind resolves the interface iface with the implementation impl, both are given as nil pointers,
+// e.g. Bind((*logx.Logger)(nil), (*fileLogger)(nil)), name applies to the interface
+func (c *Container) Bind(iface, impl interface{}, name ...string) error {
+	it := reflect.TypeOf(iface)
+
+	if it == nil || it.Kind() != reflect.Ptr || it.Elem().Kind() != reflect.Interface {
+		return errors.New("in mgboot.Container.Bind, iface must be a nil pointer to interface")
+	}
+
+	it = it.Elem()
+	implType := reflect.TypeOf(impl)
+
+	if implType == nil {
+		return errors.New("in mgboot.Container.Bind, impl must not be nil")
+	}
+
+	if implType.Kind() == reflect.Ptr && implType.Elem().Kind() == reflect.Interface {
+		implType = implType.Elem()
+	}
+
+	if !implType.Implements(it) {
+		return fmt.Errorf("in mgboot.Container.Bind, %s does not implement %s", implType, it)
+	}
+
+	target := serviceKey{typ: implType}
+
+	c.register(&serviceProvider{
+		key:    serviceKey{typ: it, name: firstServiceName(name)},
+		scope:  ServiceScope.Transient,
+		target: &target,
+	})
+
+	return nil
+}
+
+func (c *Container) Has(typ reflect.Type, name ...string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.providers[serviceKey{typ: typ, name: firstServiceName(name)}]
+	return ok
+}
+
+// Resolve fills target, a pointer to the wanted type, request scoped services can not be resolved here
+func (c *Container) Resolve(target interface{}, name ...string) error {
+	return c.resolveInto(nil, target, firstServiceName(name))
+}
+
+// ResolveWithContext resolves within the request scope of ctx, see MidRequestScope
+func (c *Container) ResolveWithContext(ctx *fiber.Ctx, target interface{}, name ...string) error {
+	return c.resolveInto(ctx, target, firstServiceName(name))
+}
+
+// Populate fills the exported fields of a struct pointer tagged with inject, the tag value is the service name
+func (c *Container) Populate(target interface{}, ctx ...*fiber.Ctx) error {
+	rv := reflect.ValueOf(target)
+
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("in mgboot.Container.Populate, target must be a non-nil pointer to struct")
+	}
+
+	var fc *fiber.Ctx
+
+	if len(ctx) > 0 {
+		fc = ctx[0]
+	}
+
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name, ok := field.Tag.Lookup("inject")
+
+		if !ok || field.PkgPath != "" {
+			continue
+		}
+
+		value, err := c.resolve(fc, serviceKey{typ: field.Type, name: name}, nil)
+
+		if err != nil {
+			return err
+		}
+
+		rv.Field(i).Set(value)
+	}
+
+	return nil
+}
+
+// Invoke calls fn with its arguments resolved from the container, the error returned by fn is passed through
+func (c *Container) Invoke(fn interface{}, ctx ...*fiber.Ctx) error {
+	var fc *fiber.Ctx
+
+	if len(ctx) > 0 {
+		fc = ctx[0]
+	}
+
+	fv := reflect.ValueOf(fn)
+
+	if fv.Kind() != reflect.Func {
+		return errors.New("in mgboot.Container.Invoke, fn must be a function")
+	}
+
+	args, err := c.resolveArgs(fc, fv.Type(), nil)
+
+	if err != nil {
+		return err
+	}
+
+	return callResultError(fv.Call(args))
+}
+
+// Handler turns fn into a fiber handler, fn takes *fiber.Ctx and services in any order and returns error or nothing,
+// services are resolved on every request so that request scoped ones are fresh
+func (c *Container) Handler(fn interface{}) fiber.Handler {
+	fv := reflect.ValueOf(fn)
+
+	if fv.Kind() != reflect.Func {
+		panic("in mgboot.Container.Handler, fn must be a function")
+	}
+
+	ft := fv.Type()
+
+	if ft.NumOut() > 1 || (ft.NumOut() == 1 && ft.Out(0) != errorType) {
+		panic("in mgboot.Container.Handler, fn must return error or nothing")
+	}
+
+	for i := 0; i < ft.NumIn(); i++ {
+		if ft.In(i) == fiberCtxType {
+			continue
+		}
+
+		if !c.Has(ft.In(i)) {
+			panic(fmt.Sprintf("in mgboot.Container.Handler, no provider for %s", ft.In(i)))
+		}
+	}
+
+	return func(ctx *fiber.Ctx) error {
+		scope, created := c.requestScope(ctx)
+
+		if created {
+			defer c.releaseRequestScope(ctx, scope)
+		}
+
+		args, err := c.resolveArgs(ctx, ft, nil)
+
+		if err != nil {
+			return err
+		}
+
+		return callResultError(fv.Call(args))
+	}
+}
+
+// Validate checks that every dependency has a provider, that there is no cycle
+// and that no singleton depends on a request scoped service
+func (c *Container) Validate() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	state := map[serviceKey]int{}
+
+	var visit func(key serviceKey, path []serviceKey) error
+
+	visit = func(key serviceKey, path []serviceKey) error {
+		path = append(path, key)
+
+		switch state[key] {
+		case 1:
+			return newDependencyCycleError(path)
+		case 2:
+			return nil
+		}
+
+		p, ok := c.providers[key]
+
+		if !ok {
+			return fmt.Errorf("in mgboot.Container, no provider for %s, required by %s", key, path[len(path)-2])
+		}
+
+		state[key] = 1
+
+		for _, dep := range p.dependencyKeys() {
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+
+			if p.scope == ServiceScope.Singleton && c.effectiveScope(dep) == ServiceScope.Request {
+				return fmt.Errorf("in mgboot.Container, singleton %s depends on request scoped %s", key, dep)
+			}
+		}
+
+		state[key] = 2
+		return nil
+	}
+
+	for _, key := range c.order {
+		if err := visit(key, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Start validates the container, creates the singletons and calls OnStart on them in dependency order,
+// when a hook fails the services started before are stopped again
+func (c *Container) Start(ctx context.Context) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	c.mu.RLock()
+	keys := make([]serviceKey, 0, len(c.order))
+
+	for _, key := range c.order {
+		if p := c.providers[key]; p.scope == ServiceScope.Singleton {
+			keys = append(keys, key)
+		}
+	}
+
+	c.mu.RUnlock()
+
+	for _, key := range keys {
+		if _, err := c.resolve(nil, key, nil); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	created := make([]*serviceProvider, len(c.created))
+	copy(created, c.created)
+	c.mu.Unlock()
+
+	for _, p := range created {
+		if c.isStarted(p) {
+			continue
+		}
+
+		if starter, ok := p.instance.Interface().(Starter); ok {
+			if err := starter.OnStart(ctx); err != nil {
+				c.Stop(ctx)
+				return fmt.Errorf("in mgboot.Container, OnStart of %s failed: %v", p.key, err)
+			}
+		}
+
+		c.mu.Lock()
+		c.started = append(c.started, p)
+		c.mu.Unlock()
+	}
+
+	return nil
+}
+
+// Stop calls OnStop on the started services in reverse order, all hooks run even if some of them fail
+func (c *Container) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	started := c.started
+	c.started = nil
+	c.mu.Unlock()
+
+	messages := make([]string, 0)
+
+	for i := len(started) - 1; i >= 0; i-- {
+		stopper, ok := started[i].instance.Interface().(Stopper)
+
+		if !ok {
+			continue
+		}
+
+		if err := stopper.OnStop(ctx); err != nil {
+			messages = append(messages, fmt.Sprintf("%s: %v", started[i].key, err))
+		}
+	}
+
+	if len(messages) > 0 {
+		return errors.New("in mgboot.Container, OnStop failed: " + strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+func (c *Container) provide(name string, constructor interface{}, scope ...int) error {
+	fv := reflect.ValueOf(constructor)
+
+	if fv.Kind() != reflect.Func {
+		return errors.New("in mgboot.Container.Provide, constructor must be a function")
+	}
+
+	ft := fv.Type()
+	var returnsErr bool
+
+	switch {
+	case ft.NumOut() == 1 && ft.Out(0) != errorType:
+	case ft.NumOut() == 2 && ft.Out(0) != errorType && ft.Out(1) == errorType:
+		returnsErr = true
+	default:
+		return fmt.Errorf("in mgboot.Container.Provide, constructor must return T or (T, error), got %s", ft)
+	}
+
+	if ft.IsVariadic() {
+		return errors.New("in mgboot.Container.Provide, variadic constructors are not supported")
+	}
+
+	_scope := ServiceScope.Singleton
+
+	if len(scope) > 0 {
+		switch scope[0] {
+		case ServiceScope.Singleton, ServiceScope.Request, ServiceScope.Transient:
+			_scope = scope[0]
+		default:
+			return fmt.Errorf("in mgboot.Container.Provide, unknown scope %d", scope[0])
+		}
+	}
+
+	deps := make([]reflect.Type, 0, ft.NumIn())
+
+	for i := 0; i < ft.NumIn(); i++ {
+		deps = append(deps, ft.In(i))
+	}
+
+	c.register(&serviceProvider{
+		key:        serviceKey{typ: ft.Out(0), name: name},
+		scope:      _scope,
+		ctor:       fv,
+		deps:       deps,
+		returnsErr: returnsErr,
+	})
+
+	return nil
+}
+
+// register replaces an earlier provider of the same key, that is how builtin components are overridden
+func (c *Container) register(p *serviceProvider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.providers[p.key]; !ok {
+		c.order = append(c.order, p.key)
+	}
+
+	c.providers[p.key] = p
+}
+
+func (c *Container) resolveInto(ctx *fiber.Ctx, target interface{}, name string) error {
+	rv := reflect.ValueOf(target)
+
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("in mgboot.Container.Resolve, target must be a non-nil pointer")
+	}
+
+	value, err := c.resolve(ctx, serviceKey{typ: rv.Type().Elem(), name: name}, nil)
+
+	if err != nil {
+		return err
+	}
+
+	rv.Elem().Set(value)
+	return nil
+}
+
+func (c *Container) resolve(ctx *fiber.Ctx, key serviceKey, path []serviceKey) (reflect.Value, error) {
+	for _, k := range path {
+		if k == key {
+			return reflect.Value{}, newDependencyCycleError(append(path, key))
+		}
+	}
+
+	path = append(path, key)
+	c.mu.RLock()
+	p, ok := c.providers[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		if len(path) > 1 {
+			return reflect.Value{}, fmt.Errorf("in mgboot.Container, no provider for %s, required by %s", key, path[len(path)-2])
+		}
+
+		return reflect.Value{}, fmt.Errorf("in mgboot.Container, no provider for %s", key)
+	}
+
+	if p.target != nil {
+		value, err := c.resolve(ctx, *p.target, path)
+
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		return value.Convert(key.typ), nil
+	}
+
+	switch p.scope {
+	case ServiceScope.Singleton:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if p.created {
+			return p.instance, nil
+		}
+
+		value, err := c.construct(nil, p, path)
+
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		p.created = true
+		p.instance = value
+		c.mu.Lock()
+		c.created = append(c.created, p)
+		c.mu.Unlock()
+		return value, nil
+	case ServiceScope.Request:
+		if ctx == nil {
+			return reflect.Value{}, fmt.Errorf("in mgboot.Container, request scoped %s resolved outside of a request", key)
+		}
+
+		scope, _ := c.requestScope(ctx)
+		scope.mu.Lock()
+		value, ok := scope.instances[key]
+		scope.mu.Unlock()
+
+		if ok {
+			return value, nil
+		}
+
+		value, err := c.construct(ctx, p, path)
+
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		scope.mu.Lock()
+		scope.instances[key] = value
+		scope.order = append(scope.order, value)
+		scope.mu.Unlock()
+		return value, nil
+	}
+
+	return c.construct(ctx, p, path)
+}
+
+func (c *Container) construct(ctx *fiber.Ctx, p *serviceProvider, path []serviceKey) (reflect.Value, error) {
+	args, err := c.resolveArgs(ctx, p.ctor.Type(), path)
+
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	results := p.ctor.Call(args)
+
+	if p.returnsErr && !results[1].IsNil() {
+		return reflect.Value{}, fmt.Errorf("in mgboot.Container, constructor of %s failed: %v", p.key, results[1].Interface())
+	}
+
+	return results[0], nil
+}
+
+func (c *Container) resolveArgs(ctx *fiber.Ctx, ft reflect.Type, path []serviceKey) ([]reflect.Value, error) {
+	args := make([]reflect.Value, 0, ft.NumIn())
+
+	for i := 0; i < ft.NumIn(); i++ {
+		if ft.In(i) == fiberCtxType {
+			if ctx == nil {
+				return nil, errors.New("in mgboot.Container, *fiber.Ctx is only available within a request")
+			}
+
+			args = append(args, reflect.ValueOf(ctx))
+			continue
+		}
+
+		value, err := c.resolve(ctx, serviceKey{typ: ft.In(i)}, path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, value)
+	}
+
+	return args, nil
+}
+
+func (c *Container) requestScope(ctx *fiber.Ctx) (*requestScope, bool) {
+	if scope, ok := ctx.Locals("diRequestScope").(*requestScope); ok {
+		return scope, false
+	}
+
+	scope := &requestScope{instances: map[serviceKey]reflect.Value{}}
+	ctx.Locals("diRequestScope", scope)
+	return scope, true
+}
+
+// releaseRequestScope calls OnStop on the request scoped services in reverse order, failures are only logged
+func (c *Container) releaseRequestScope(ctx *fiber.Ctx, scope *requestScope) {
+	ctx.Locals("diRequestScope", nil)
+	scope.mu.Lock()
+	order := scope.order
+	scope.mu.Unlock()
+
+	for i := len(order) - 1; i >= 0; i-- {
+		stopper, ok := order[i].Interface().(Stopper)
+
+		if !ok {
+			continue
+		}
+
+		if err := stopper.OnStop(ctx.UserContext()); err != nil {
+			RuntimeLogger().Errorf("OnStop of request scoped %s failed: %v", order[i].Type(), err)
+		}
+	}
+}
+
+func (c *Container) effectiveScope(key serviceKey) int {
+	p, ok := c.providers[key]
+
+	for ok && p.target != nil {
+		p, ok = c.providers[*p.target]
+	}
+
+	if !ok {
+		return 0
+	}
+
+	return p.scope
+}
+
+func (c *Container) isStarted(p *serviceProvider) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, started := range c.started {
+		if started == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *serviceProvider) dependencyKeys() []serviceKey {
+	if p.target != nil {
+		return []serviceKey{*p.target}
+	}
+
+	keys := make([]serviceKey, 0, len(p.deps))
+
+	for _, dep := range p.deps {
+		if dep == fiberCtxType {
+			continue
+		}
+
+		keys = append(keys, serviceKey{typ: dep})
+	}
+
+	return keys
+}
+
+func newDependencyCycleError(path []serviceKey) error {
+	names := make([]string, 0, len(path))
+
+	for _, key := range path {
+		names = append(names, key.String())
+	}
+
+	return errors.New("in mgboot.Container, dependency cycle: " + strings.Join(names, " -> "))
+}
+
+func callResultError(results []reflect.Value) error {
+	if len(results) < 1 {
+		return nil
+	}
+
+	last := results[len(results)-1]
+
+	if last.Type() == errorType && !last.IsNil() {
+		return last.Interface().(error)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package mgboot
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/logx"
+)
+
+type contextLogger struct {
+	logger logx.Logger
+	prefix string
+}
+
+// NewContextLogger decorates logger so that every message is prefixed with the request id of ctx.
+func NewContextLogger(logger logx.Logger, arg0 interface{}) logx.Logger {
+	var requestId string
+
+	if ctx, ok := arg0.(*fiber.Ctx); ok {
+		requestId = GetRequestId(ctx)
+	} else if tc, ok := arg0.(*TraceContext); ok && tc != nil {
+		requestId = tc.RequestId()
+	} else if s1, ok := arg0.(string); ok {
+		requestId = s1
+	}
+
+	if requestId == "" {
+		return logger
+	}
+
+	return &contextLogger{logger: logger, prefix: "[" + requestId + "] "}
+}
+
+func (l *contextLogger) Log(level interface{}, args ...interface{}) {
+	l.logger.Log(level, l.prepend(args)...)
+}
+
+func (l *contextLogger) Logf(level interface{}, format string, args ...interface{}) {
+	l.logger.Logf(level, l.prefix+format, args...)
+}
+
+func (l *contextLogger) Trace(args ...interface{}) {
+	l.logger.Trace(l.prepend(args)...)
+}
+
+func (l *contextLogger) Tracef(format string, args ...interface{}) {
+	l.logger.Tracef(l.prefix+format, args...)
+}
+
+func (l *contextLogger) Debug(args ...interface{}) {
+	l.logger.Debug(l.prepend(args)...)
+}
+
+func (l *contextLogger) Debugf(format string, args ...interface{}) {
+	l.logger.Debugf(l.prefix+format, args...)
+}
+
+func (l *contextLogger) Info(args ...interface{}) {
+	l.logger.Info(l.prepend(args)...)
+}
+
+func (l *contextLogger) Infof(format string, args ...interface{}) {
+	l.logger.Infof(l.prefix+format, args...)
+}
+
+func (l *contextLogger) Warn(args ...interface{}) {
+	l.logger.Warn(l.prepend(args)...)
+}
+
+func (l *contextLogger) Warnf(format string, args ...interface{}) {
+	l.logger.Warnf(l.prefix+format, args...)
+}
+
+func (l *contextLogger) Error(args ...interface{}) {
+	l.logger.Error(l.prepend(args)...)
+}
+
+func (l *contextLogger) Errorf(format string, args ...interface{}) {
+	l.logger.Errorf(l.prefix+format, args...)
+}
+
+func (l *contextLogger) Panic(args ...interface{}) {
+	l.logger.Panic(l.prepend(args)...)
+}
+
+func (l *contextLogger) Panicf(format string, args ...interface{}) {
+	l.logger.Panicf(l.prefix+format, args...)
+}
+
+func (l *contextLogger) Fatal(args ...interface{}) {
+	l.logger.Fatal(l.prepend(args)...)
+}
+
+func (l *contextLogger) Fatalf(format string, args ...interface{}) {
+	l.logger.Fatalf(l.prefix+format, args...)
+}
+
+func (l *contextLogger) prepend(args []interface{}) []interface{} {
+	return append([]interface{}{l.prefix}, args...)
+}
@@ -2,6 +2,7 @@ package mgboot
 
 import (
 	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"strings"
 	"time"
 )
 
@@ -101,3 +102,27 @@ func (st *CorsSettings) ExposedHeaders() []string {
 func (st *CorsSettings) MaxAge() time.Duration {
 	return st.maxAge
 }
+
+// IsOriginAllowed supports * and subdomain wildcards like https://*.example.com
+func (st *CorsSettings) IsOriginAllowed(origin string) bool {
+	origin = strings.ToLower(strings.TrimSpace(origin))
+
+	for _, s1 := range st.allowedOrigins {
+		s1 = strings.ToLower(strings.TrimSpace(s1))
+
+		if s1 == "*" || s1 == origin {
+			return true
+		}
+
+		if idx := strings.Index(s1, "*."); idx >= 0 {
+			prefix := s1[:idx]
+			suffix := s1[idx+1:]
+
+			if strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) && len(origin) > len(prefix)+len(suffix) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -27,7 +27,7 @@ func DefaultErrorHandler() func(ctx *fiber.Ctx, err error) error {
 		AddPoweredBy(ctx)
 
 		if handler == nil {
-			RuntimeLogger().Error(errorx.Stacktrace(err))
+			NewContextLogger(RuntimeLogger(), ctx).Error(errorx.Stacktrace(err))
 			ctx.Type("html", "utf8")
 			ctx.Status(fiber.StatusInternalServerError).Send([]byte{})
 			return nil
@@ -38,6 +38,11 @@ func DefaultErrorHandler() func(ctx *fiber.Ctx, err error) error {
 		}
 
 		payload := handler.HandleError(err)
+
+		if profile := GetCurrentRouteProfile(ctx); profile != nil && profile.ErrorEnvelope() != nil {
+			payload = profile.ErrorEnvelope()(ctx, err, payload)
+		}
+
 		statusCode, contents := payload.GetContents()
 
 		if statusCode >= 400 {
@@ -46,6 +51,7 @@ func DefaultErrorHandler() func(ctx *fiber.Ctx, err error) error {
 			return nil
 		}
 
+		ctx.Locals("ResponsePayload", payload)
 		contentType := payload.GetContentType()
 
 		if contentType != "" {
@@ -84,6 +84,36 @@ func GetQueryParams(ctx *fiber.Ctx) map[string]string {
 	return map1
 }
 
+func GetQueryValues(ctx *fiber.Ctx) map[string][]string {
+	s1 := utils.CopyString(ctx.OriginalURL())
+
+	if !strings.Contains(s1, "?") {
+		return map[string][]string{}
+	}
+
+	values, err := url.ParseQuery(stringx.SubstringAfter(s1, "?"))
+
+	if err != nil || len(values) < 1 {
+		return map[string][]string{}
+	}
+
+	map1 := map[string][]string{}
+
+	for key, parts := range values {
+		if key == "" {
+			continue
+		}
+
+		map1[key] = parts
+	}
+
+	return map1
+}
+
+func GetQueryParamTree(ctx *fiber.Ctx) map[string]interface{} {
+	return buildParamTree(GetQueryValues(ctx))
+}
+
 func GetQueryString(ctx *fiber.Ctx, urlencode ...bool) string {
 	if len(urlencode) < 1 || !urlencode[0] {
 		s1 := utils.CopyString(ctx.OriginalURL())
@@ -95,19 +125,13 @@ func GetQueryString(ctx *fiber.Ctx, urlencode ...bool) string {
 		return stringx.SubstringAfter(s1, "?")
 	}
 	
-	map1 := GetQueryParams(ctx)
+	map1 := GetQueryValues(ctx)
 	
 	if len(map1) < 1 {
 		return ""
 	}
 
-	values := url.Values{}
-
-	for key, value := range map1 {
-		values[key] = []string{value}
-	}
-
-	return values.Encode()
+	return url.Values(map1).Encode()
 }
 
 func GetRequestUrl(ctx *fiber.Ctx, withQueryString ...bool) string {
@@ -191,30 +215,69 @@ func GetFormData(ctx *fiber.Ctx) map[string]string {
 	return map1
 }
 
-func GetClientIp(ctx *fiber.Ctx) string {
-	ips := ctx.IPs()
+func GetFormValues(ctx *fiber.Ctx) map[string][]string {
+	map1 := map[string][]string{}
+	isPost := ctx.Request().Header.IsPost()
 
-	if len(ips) > 0 {
-		for _, s1 := range ips {
-			if stringx.RegexMatch(s1, "^[0-9.]+$") {
-				return s1
-			}
-		}
+	if !isPost {
+		return map1
 	}
 
-	ip := ctx.Get("X-Real-IP")
+	contentType := strings.ToLower(ctx.Get(fiber.HeaderContentType))
+	isPostForm := strings.Contains(contentType, fiber.MIMEApplicationForm)
+	isMultipartForm := strings.Contains(contentType, fiber.MIMEMultipartForm)
+
+	if isPostForm {
+		ctx.Request().PostArgs().VisitAll(func(keyBytes, valueBytes []byte) {
+			var key string
+
+			if len(keyBytes) > 0 {
+				key = string(utils.CopyBytes(keyBytes))
+			}
 
-	if ip == "" {
-		ip = ctx.IP()
+			if key == "" {
+				return
+			}
+
+			var value string
+
+			if len(valueBytes) > 0 {
+				value = string(utils.CopyBytes(valueBytes))
+			}
+
+			map1[key] = append(map1[key], value)
+		})
+
+		return map1
 	}
 
-	parts := stringx.SplitWithRegexp(strings.TrimSpace(ip), RegexConst.CommaSep)
+	if isMultipartForm {
+		form, err := ctx.MultipartForm()
 
-	if len(parts) < 1 {
-		return ""
+		if err != nil {
+			return map1
+		}
+
+		for key, values := range form.Value {
+			if key == "" {
+				continue
+			}
+
+			map1[key] = values
+		}
+
+		return map1
 	}
 
-	return strings.TrimSpace(parts[0])
+	return map1
+}
+
+func GetFormDataTree(ctx *fiber.Ctx) map[string]interface{} {
+	return buildParamTree(GetFormValues(ctx))
+}
+
+func GetClientIp(ctx *fiber.Ctx) string {
+	return resolveClientIp(ctx)
 }
 
 func Pathvariable(ctx *fiber.Ctx, name string, defaultValue ...interface{}) string {
@@ -322,11 +385,52 @@ func ReqParam(ctx *fiber.Ctx, name string, mode int, defaultValue ...interface{}
 		return dv
 	}
 
-	if mode != ReqParamSecurityMode.None {
-		value = stringx.StripTags(value)
+	return applySecurityMode(value, mode)
+}
+
+func ReqParamSlice(ctx *fiber.Ctx, name string, mode ...int) []string {
+	_mode := ReqParamSecurityMode.StripTags
+
+	if len(mode) > 0 {
+		_mode = mode[0]
+	}
+
+	formValues := GetFormValues(ctx)
+	queryValues := GetQueryValues(ctx)
+	var parts []string
+
+	for _, key := range []string{name, name + "[]"} {
+		if a1 := formValues[key]; len(a1) > 0 {
+			parts = a1
+			break
+		}
+
+		if a1 := queryValues[key]; len(a1) > 0 {
+			parts = a1
+			break
+		}
+	}
+
+	values := make([]string, 0, len(parts))
+
+	for _, value := range parts {
+		values = append(values, applySecurityMode(value, _mode))
 	}
 
-	return value
+	return values
+}
+
+func ReqParamIntSlice(ctx *fiber.Ctx, name string) []int {
+	parts := ReqParamSlice(ctx, name, ReqParamSecurityMode.None)
+	values := make([]int, 0, len(parts))
+
+	for _, s1 := range parts {
+		if n1, err := castx.ToIntE(strings.TrimSpace(s1)); err == nil {
+			values = append(values, n1)
+		}
+	}
+
+	return values
 }
 
 func ReqParamBool(ctx *fiber.Ctx, name string, defaultValue ...interface{}) bool {
@@ -399,6 +503,8 @@ func ReqParamFloat64(ctx *fiber.Ctx, name string, defaultValue ...interface{}) f
 	return castx.ToFloat64(s1, dv)
 }
 
+// GetJwt parses the token with the public key of the jwt settings of the matched route,
+// the global public key is used when the route has neither JwtAuth nor a profile with jwt settings
 func GetJwt(ctx *fiber.Ctx) *jwt.Token {
 	token := strings.TrimSpace(ctx.Get(fiber.HeaderAuthorization))
 	token = stringx.RegexReplace(token, `[\x20\t]+`, " ")
@@ -411,10 +517,29 @@ func GetJwt(ctx *fiber.Ctx) *jwt.Token {
 		return nil
 	}
 
-	tk, _ := ParseJsonWebToken(token)
+	var pubpem string
+
+	if settings := currentJwtSettings(ctx); settings != nil {
+		pubpem = settings.PublicKeyPemFile()
+	}
+
+	tk, _ := ParseJsonWebToken(token, pubpem)
 	return tk
 }
 
+// currentJwtSettings returns the settings verified by JwtAuth, or those of the route profile
+func currentJwtSettings(ctx *fiber.Ctx) *JwtSettings {
+	if key, ok := ctx.Locals("JwtSettingsKey").(string); ok && key != "" {
+		return GetJwtSettings(key)
+	}
+
+	if profile := GetCurrentRouteProfile(ctx); profile != nil && profile.JwtKey() != "" {
+		return GetJwtSettings(profile.JwtKey())
+	}
+
+	return nil
+}
+
 func GetRawBody(ctx *fiber.Ctx) []byte {
 	isPost := ctx.Request().Header.IsPost()
 	isPut := ctx.Request().Header.IsPut()
@@ -431,7 +556,7 @@ func GetRawBody(ctx *fiber.Ctx) []byte {
 		buf := utils.CopyBytes(ctx.Body())
 
 		if AppConf.GetBoolean("logging.logGetRawBody") {
-			RuntimeLogger().Debug("raw body: " + string(buf))
+			RuntimeLogger().Debug("raw body: " + MaskBody(buf, ctx.Get(fiber.HeaderContentType)))
 		}
 
 		return buf
@@ -449,22 +574,16 @@ func GetRawBody(ctx *fiber.Ctx) []byte {
 		return make([]byte, 0)
 	}
 
-	formData := GetFormData(ctx)
+	formValues := GetFormValues(ctx)
 
-	if len(formData) < 1 {
+	if len(formValues) < 1 {
 		return make([]byte, 0)
 	}
 
-	values := url.Values{}
-
-	for name, value := range formData {
-		values[name] = []string{value}
-	}
-
-	contents := values.Encode()
+	contents := url.Values(formValues).Encode()
 
 	if AppConf.GetBoolean("logging.logGetRawBody") {
-		RuntimeLogger().Debug("raw body via form data: " + contents)
+		RuntimeLogger().Debug("raw body via form data: " + MaskBody([]byte(contents), fiber.MIMEApplicationForm))
 	}
 
 	return []byte(contents)
@@ -520,13 +639,13 @@ func GetMap(ctx *fiber.Ctx, rules ...interface{}) map[string]interface{} {
 	}
 
 	if isGet {
-		map1 := GetQueryParams(ctx)
+		map1 := GetQueryParamTree(ctx)
 
 		if len(_rules) < 1 {
-			return castx.ToStringMap(map1)
+			return map1
 		}
 
-		return getMapWithRules(castx.ToStringMap(map1), _rules)
+		return getMapWithRules(map1, _rules)
 	}
 
 	if !isPost {
@@ -545,16 +664,9 @@ func GetMap(ctx *fiber.Ctx, rules ...interface{}) map[string]interface{} {
 		return getMapWithRules(ctx, _rules)
 	}
 
-	map1 := map[string]interface{}{}
-	queryParams := GetQueryParams(ctx)
-
-	for key, value := range queryParams {
-		map1[key] = value
-	}
-
-	formData := GetFormData(ctx)
+	map1 := GetQueryParamTree(ctx)
 
-	for key, value := range formData {
+	for key, value := range GetFormDataTree(ctx) {
 		map1[key] = value
 	}
 
@@ -572,7 +684,7 @@ func DtoBind(ctx *fiber.Ctx, dto interface{}) error {
 }
 
 func GetUploadedFile(ctx *fiber.Ctx, formFieldName string) *multipart.FileHeader {
-	if fh, err := ctx.FormFile(formFieldName); err != nil {
+	if fh, err := ctx.FormFile(formFieldName); err == nil {
 		return fh
 	}
 
@@ -668,17 +780,15 @@ func getMapWithRules(arg0 interface{}, rules []string) map[string]interface{} {
 			}
 		} else if len(srcMap) > 0 {
 			paramValue = srcMap[srcKey]
+
+			if items, ok := paramValue.([]interface{}); ok && len(items) > 0 {
+				paramValue = items[0]
+			}
 		}
 
 		switch typ {
 		case 1:
-			value := castx.ToString(paramValue)
-
-			if mode != 0 {
-				dstMap[dstKey] = stringx.StripTags(value)
-			} else {
-				dstMap[dstKey] = value
-			}
+			dstMap[dstKey] = applySecurityMode(castx.ToString(paramValue), mode)
 		case 2:
 			var value int
 
@@ -704,3 +814,30 @@ func getMapWithRules(arg0 interface{}, rules []string) map[string]interface{} {
 
 	return dstMap
 }
+
+func resolveStatusCode(ctx *fiber.Ctx, err error) int {
+	if err == nil {
+		return ctx.Response().StatusCode()
+	}
+
+	if ex, ok := err.(*fiber.Error); ok {
+		return ex.Code
+	}
+
+	return fiber.StatusInternalServerError
+}
+
+func resolveRoutePath(ctx *fiber.Ctx, statusCode int) string {
+	route := "unmatched"
+
+	if r := ctx.Route(); r != nil && r.Path != "" {
+		route = r.Path
+	}
+
+	// when nothing matched, fiber reports the last middleware route, don't let 404 scans blow up the label set
+	if statusCode == fiber.StatusNotFound && !strings.ContainsAny(route, ":*") && !strings.EqualFold(route, ctx.Path()) {
+		route = "unmatched"
+	}
+
+	return route
+}
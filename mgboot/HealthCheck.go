@@ -0,0 +1,124 @@
+package mgboot
+
+import (
+	"context"
+	"fmt"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"sync"
+	"time"
+)
+
+type HealthCheck struct {
+	name     string
+	check    HealthCheckFunc
+	timeout  time.Duration
+	cacheTtl time.Duration
+	liveness bool
+	mu       sync.Mutex
+	last     *HealthCheckResult
+}
+
+// NewHealthCheck supported keys: timeout, cacheTtl, liveness, the check is included in /health/live when liveness is set,
+// otherwise only in /health/ready, timeout and cacheTtl default to those of HealthSettings
+func NewHealthCheck(name string, check HealthCheckFunc, settings ...map[string]interface{}) *HealthCheck {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	cacheTtl := time.Duration(-1)
+
+	if d1, err := castx.ToDurationE(_settings["cacheTtl"]); err == nil && d1 >= 0 {
+		cacheTtl = d1
+	}
+
+	return &HealthCheck{
+		name:     name,
+		check:    check,
+		timeout:  castx.ToDuration(_settings["timeout"]),
+		cacheTtl: cacheTtl,
+		liveness: castx.ToBool(_settings["liveness"]),
+	}
+}
+
+func (hc *HealthCheck) Name() string {
+	return hc.name
+}
+
+func (hc *HealthCheck) Liveness() bool {
+	return hc.liveness
+}
+
+// Run returns the cached result when the last run is within the cache ttl, concurrent calls share one run
+func (hc *HealthCheck) Run(ctx context.Context) *HealthCheckResult {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	st := GetHealthSettings()
+	cacheTtl := hc.cacheTtl
+
+	if cacheTtl < 0 {
+		cacheTtl = st.CacheTtl()
+	}
+
+	if hc.last != nil && cacheTtl > 0 && time.Since(hc.last.checkedAt) < cacheTtl {
+		result := *hc.last
+		result.cached = true
+		return &result
+	}
+
+	timeout := hc.timeout
+
+	if timeout <= 0 {
+		timeout = st.Timeout()
+	}
+
+	start := time.Now()
+	details, err := hc.runWithTimeout(ctx, timeout)
+
+	result := &HealthCheckResult{
+		name:      hc.name,
+		up:        err == nil,
+		details:   details,
+		duration:  time.Since(start),
+		checkedAt: start,
+	}
+
+	if err != nil {
+		result.err = err.Error()
+	}
+
+	hc.last = result
+	return result
+}
+
+func (hc *HealthCheck) runWithTimeout(ctx context.Context, timeout time.Duration) (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type checkResult struct {
+		details map[string]interface{}
+		err     error
+	}
+
+	ch := make(chan checkResult, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				ch <- checkResult{err: fmt.Errorf("panic: %v", r)}
+			}
+		}()
+
+		details, err := hc.check(ctx)
+		ch <- checkResult{details: details, err: err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.details, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timeout after %s", timeout)
+	}
+}
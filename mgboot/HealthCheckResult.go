@@ -0,0 +1,72 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/enum/DatetimeFormat"
+	"time"
+)
+
+type HealthCheckResult struct {
+	name      string
+	up        bool
+	err       string
+	details   map[string]interface{}
+	duration  time.Duration
+	checkedAt time.Time
+	cached    bool
+}
+
+func (r *HealthCheckResult) Name() string {
+	return r.name
+}
+
+func (r *HealthCheckResult) Up() bool {
+	return r.up
+}
+
+func (r *HealthCheckResult) Status() string {
+	if r.up {
+		return "UP"
+	}
+
+	return "DOWN"
+}
+
+func (r *HealthCheckResult) Error() string {
+	return r.err
+}
+
+func (r *HealthCheckResult) Details() map[string]interface{} {
+	return r.details
+}
+
+func (r *HealthCheckResult) Duration() time.Duration {
+	return r.duration
+}
+
+func (r *HealthCheckResult) CheckedAt() time.Time {
+	return r.checkedAt
+}
+
+// Cached reports whether the result is reused from a previous run within the cache ttl of the check
+func (r *HealthCheckResult) Cached() bool {
+	return r.cached
+}
+
+func (r *HealthCheckResult) ToMap() map[string]interface{} {
+	map1 := map[string]interface{}{
+		"status":     r.Status(),
+		"durationMs": r.duration.Milliseconds(),
+		"checkedAt":  r.checkedAt.Format(DatetimeFormat.Full),
+		"cached":     r.cached,
+	}
+
+	if r.err != "" {
+		map1["error"] = r.err
+	}
+
+	if len(r.details) > 0 {
+		map1["details"] = r.details
+	}
+
+	return map1
+}
@@ -0,0 +1,77 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"strings"
+	"time"
+)
+
+type HealthSettings struct {
+	livePath    string
+	readyPath   string
+	timeout     time.Duration
+	cacheTtl    time.Duration
+	showDetails bool
+}
+
+// NewHealthSettings supported keys: livePath, readyPath, timeout, cacheTtl, showDetails,
+// timeout and cacheTtl are the defaults of the checks which do not set their own
+func NewHealthSettings(settings map[string]interface{}) *HealthSettings {
+	livePath := "/health/live"
+
+	if s1 := strings.TrimSpace(castx.ToString(settings["livePath"])); s1 != "" {
+		livePath = s1
+	}
+
+	readyPath := "/health/ready"
+
+	if s1 := strings.TrimSpace(castx.ToString(settings["readyPath"])); s1 != "" {
+		readyPath = s1
+	}
+
+	timeout := 3 * time.Second
+
+	if d1 := castx.ToDuration(settings["timeout"]); d1 > 0 {
+		timeout = d1
+	}
+
+	cacheTtl := 5 * time.Second
+
+	if d1, err := castx.ToDurationE(settings["cacheTtl"]); err == nil && d1 >= 0 {
+		cacheTtl = d1
+	}
+
+	showDetails := true
+
+	if b1, err := castx.ToBoolE(settings["showDetails"]); err == nil {
+		showDetails = b1
+	}
+
+	return &HealthSettings{
+		livePath:    livePath,
+		readyPath:   readyPath,
+		timeout:     timeout,
+		cacheTtl:    cacheTtl,
+		showDetails: showDetails,
+	}
+}
+
+func (st *HealthSettings) LivePath() string {
+	return st.livePath
+}
+
+func (st *HealthSettings) ReadyPath() string {
+	return st.readyPath
+}
+
+func (st *HealthSettings) Timeout() time.Duration {
+	return st.timeout
+}
+
+func (st *HealthSettings) CacheTtl() time.Duration {
+	return st.cacheTtl
+}
+
+func (st *HealthSettings) ShowDetails() bool {
+	return st.showDetails
+}
@@ -0,0 +1,327 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/slicex"
+	"golang.org/x/net/html"
+	"net/url"
+	"strings"
+)
+
+type HtmlPurifier struct {
+	allowedTags      map[string][]string
+	globalAttributes []string
+	allowedSchemes   []string
+	requireNoopener  bool
+	dropContentsTags []string
+}
+
+func NewHtmlPurifier(settings map[string]interface{}) *HtmlPurifier {
+	allowedTags := map[string][]string{
+		"a":          {"href", "title", "target", "rel"},
+		"img":        {"src", "alt", "title", "width", "height"},
+		"p":          {},
+		"br":         {},
+		"hr":         {},
+		"b":          {},
+		"i":          {},
+		"u":          {},
+		"s":          {},
+		"strong":     {},
+		"em":         {},
+		"span":       {},
+		"div":        {},
+		"h1":         {},
+		"h2":         {},
+		"h3":         {},
+		"h4":         {},
+		"h5":         {},
+		"h6":         {},
+		"ul":         {},
+		"ol":         {},
+		"li":         {},
+		"blockquote": {},
+		"pre":        {},
+		"code":       {},
+		"sub":        {},
+		"sup":        {},
+		"table":      {},
+		"thead":      {},
+		"tbody":      {},
+		"tfoot":      {},
+		"tr":         {},
+		"th":         {"colspan", "rowspan"},
+		"td":         {"colspan", "rowspan"},
+		"figure":     {},
+		"figcaption": {},
+	}
+
+	if map1, ok := settings["allowedTags"].(map[string]interface{}); ok && len(map1) > 0 {
+		allowedTags = map[string][]string{}
+
+		for tag, attrs := range map1 {
+			allowedTags[strings.ToLower(tag)] = toLowerSlice(castx.ToStringSlice(attrs))
+		}
+	} else if a1 := castx.ToStringSlice(settings["allowedTags"]); len(a1) > 0 {
+		tags := map[string][]string{}
+
+		for _, tag := range a1 {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			tags[tag] = allowedTags[tag]
+		}
+
+		allowedTags = tags
+	}
+
+	globalAttributes := []string{"class"}
+
+	if a1, ok := settings["globalAttributes"]; ok {
+		globalAttributes = toLowerSlice(castx.ToStringSlice(a1))
+	}
+
+	allowedSchemes := []string{"http", "https", "mailto"}
+
+	if a1 := castx.ToStringSlice(settings["allowedSchemes"]); len(a1) > 0 {
+		allowedSchemes = toLowerSlice(a1)
+	}
+
+	requireNoopener := true
+
+	if b1, err := castx.ToBoolE(settings["requireNoopener"]); err == nil {
+		requireNoopener = b1
+	}
+
+	return &HtmlPurifier{
+		allowedTags:      allowedTags,
+		globalAttributes: globalAttributes,
+		allowedSchemes:   allowedSchemes,
+		requireNoopener:  requireNoopener,
+		dropContentsTags: []string{"script", "style", "iframe", "object", "embed", "noscript", "template", "textarea", "select", "svg", "math"},
+	}
+}
+
+func (p *HtmlPurifier) AllowedTags() map[string][]string {
+	return p.allowedTags
+}
+
+func (p *HtmlPurifier) GlobalAttributes() []string {
+	return p.globalAttributes
+}
+
+func (p *HtmlPurifier) AllowedSchemes() []string {
+	return p.allowedSchemes
+}
+
+func (p *HtmlPurifier) Purify(contents string) string {
+	if contents == "" {
+		return ""
+	}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(contents))
+	sb := strings.Builder{}
+	openTags := make([]string, 0)
+	var dropDepth int
+	var dropTag string
+
+	for {
+		tt := tokenizer.Next()
+
+		if tt == html.ErrorToken {
+			break
+		}
+
+		token := tokenizer.Token()
+		tag := strings.ToLower(token.Data)
+
+		if dropDepth > 0 {
+			switch tt {
+			case html.StartTagToken:
+				if tag == dropTag {
+					dropDepth++
+				}
+			case html.EndTagToken:
+				if tag == dropTag {
+					dropDepth--
+				}
+			}
+
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			sb.WriteString(html.EscapeString(token.Data))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if p.isDropContentsTag(tag) {
+				if tt == html.StartTagToken {
+					dropTag = tag
+					dropDepth = 1
+				}
+
+				continue
+			}
+
+			attrs, ok := p.allowedTags[tag]
+
+			if !ok {
+				continue
+			}
+
+			sb.WriteString("<" + tag)
+			sb.WriteString(p.buildAttributes(tag, token.Attr, attrs))
+
+			if isVoidElement(tag) {
+				sb.WriteString(" />")
+				continue
+			}
+
+			// browsers ignore the slash of a non-void element, <div/> would leave the element open
+			if tt == html.SelfClosingTagToken {
+				sb.WriteString("></" + tag + ">")
+				continue
+			}
+
+			sb.WriteString(">")
+			openTags = append(openTags, tag)
+		case html.EndTagToken:
+			idx := -1
+
+			for i := len(openTags) - 1; i >= 0; i-- {
+				if openTags[i] == tag {
+					idx = i
+					break
+				}
+			}
+
+			if idx < 0 {
+				continue
+			}
+
+			for i := len(openTags) - 1; i >= idx; i-- {
+				sb.WriteString("</" + openTags[i] + ">")
+			}
+
+			openTags = openTags[:idx]
+		}
+	}
+
+	for i := len(openTags) - 1; i >= 0; i-- {
+		sb.WriteString("</" + openTags[i] + ">")
+	}
+
+	return sb.String()
+}
+
+func (p *HtmlPurifier) buildAttributes(tag string, attrs []html.Attribute, allowed []string) string {
+	sb := strings.Builder{}
+	var rel string
+	var hasHref bool
+	seen := map[string]bool{}
+
+	for _, attr := range attrs {
+		name := strings.ToLower(attr.Key)
+
+		if seen[name] || strings.HasPrefix(name, "on") || attr.Namespace != "" {
+			continue
+		}
+
+		if !slicex.InStringSlice(name, allowed) && !slicex.InStringSlice(name, p.globalAttributes) {
+			continue
+		}
+
+		value := strings.TrimSpace(attr.Val)
+
+		switch name {
+		case "href", "src", "cite", "action", "poster":
+			if !p.isAllowedUrl(value) {
+				continue
+			}
+
+			if name == "href" {
+				hasHref = true
+			}
+		case "style":
+			lower := strings.ToLower(value)
+
+			if strings.Contains(lower, "expression") || strings.Contains(lower, "url(") || strings.Contains(lower, "javascript:") {
+				continue
+			}
+		case "rel":
+			rel = value
+			seen[name] = true
+			continue
+		}
+
+		seen[name] = true
+		sb.WriteString(" " + name + `="` + html.EscapeString(value) + `"`)
+	}
+
+	if tag == "a" && hasHref && p.requireNoopener {
+		parts := strings.Fields(strings.ToLower(rel))
+
+		for _, s1 := range []string{"noopener", "noreferrer"} {
+			if !slicex.InStringSlice(s1, parts) {
+				parts = append(parts, s1)
+			}
+		}
+
+		rel = strings.Join(parts, " ")
+	}
+
+	if rel != "" && (slicex.InStringSlice("rel", allowed) || tag == "a") {
+		sb.WriteString(` rel="` + html.EscapeString(rel) + `"`)
+	}
+
+	return sb.String()
+}
+
+func (p *HtmlPurifier) isAllowedUrl(value string) bool {
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= 0x20 || r == 0x7f {
+			return -1
+		}
+
+		return r
+	}, value)
+
+	if cleaned == "" {
+		return false
+	}
+
+	u, err := url.Parse(cleaned)
+
+	if err != nil {
+		return false
+	}
+
+	if u.Scheme == "" {
+		return !strings.Contains(strings.SplitN(cleaned, "/", 2)[0], ":")
+	}
+
+	return slicex.InStringSlice(strings.ToLower(u.Scheme), p.allowedSchemes)
+}
+
+func (p *HtmlPurifier) isDropContentsTag(tag string) bool {
+	return slicex.InStringSlice(tag, p.dropContentsTags)
+}
+
+func isVoidElement(tag string) bool {
+	switch tag {
+	case "area", "base", "br", "col", "hr", "img", "input", "link", "meta", "source", "track", "wbr":
+		return true
+	}
+
+	return false
+}
+
+func toLowerSlice(list []string) []string {
+	items := make([]string, 0, len(list))
+
+	for _, s1 := range list {
+		if s1 = strings.ToLower(strings.TrimSpace(s1)); s1 != "" {
+			items = append(items, s1)
+		}
+	}
+
+	return items
+}
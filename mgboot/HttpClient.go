@@ -6,7 +6,11 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"github.com/gofiber/fiber/v2"
 	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"io"
 	"io/ioutil"
 	"mime/multipart"
@@ -24,6 +28,7 @@ type httpClient struct {
 	certificates         []tls.Certificate
 	skipServerHostVerify bool
 	timeout              time.Duration
+	traceContext         *TraceContext
 }
 
 type multipartEntry interface {
@@ -130,14 +135,32 @@ func NewFilePartFromBuffer(formFieldName, clientFileName string, buf []byte) fil
 	}
 }
 
+// @param traceSource *fiber.Ctx|*TraceContext, request id and traceparent are forwarded from it
 //noinspection GoExportedFuncWithUnexportedType
-func NewHttpClient(requestUrl string) httpClient {
-	return httpClient{
+func NewHttpClient(requestUrl string, traceSource ...interface{}) httpClient {
+	c := httpClient{
 		requestUrl:           requestUrl,
 		headers:              map[string]string{},
 		skipServerHostVerify: true,
 		timeout:              15 * time.Second,
 	}
+
+	var tc *TraceContext
+
+	if len(traceSource) > 0 {
+		if ctx, ok := traceSource[0].(*fiber.Ctx); ok {
+			tc = GetTraceContext(ctx)
+		} else if _tc, ok := traceSource[0].(*TraceContext); ok {
+			tc = _tc
+		}
+	}
+
+	if tc != nil {
+		c.traceContext = tc
+		c.SetHeaders(tc.Child().Headers())
+	}
+
+	return c
 }
 
 func (c httpClient) AddHeader(headerName string, headerValue string) httpClient {
@@ -418,6 +441,34 @@ func (c httpClient) sendRequest(method, contentType string, body io.Reader) (buf
 		c.AddHeader("Content-Type", contentType)
 	}
 
+	var span trace.Span
+
+	if TracingEnabled() {
+		_, span = StartSpan(
+			c.traceContext,
+			"HTTP "+method,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("http.method", method), attribute.String("http.url", c.requestUrl)),
+		)
+
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+
+			span.End()
+		}()
+
+		var requestId string
+
+		if c.traceContext != nil {
+			requestId = c.traceContext.RequestId()
+		}
+
+		c.SetHeaders(TraceContextFromSpan(span, requestId).Headers())
+	}
+
 	req, err := c.buildRequest(method, body)
 
 	if err != nil {
@@ -434,6 +485,10 @@ func (c httpClient) sendRequest(method, contentType string, body io.Reader) (buf
 	buf, err = ioutil.ReadAll(resp.Body)
 	statusCode := resp.StatusCode
 
+	if span != nil {
+		span.SetAttributes(attribute.Int("http.status_code", statusCode))
+	}
+
 	if statusCode >= 400 {
 		reason, ok := httpErrors[statusCode]
 		var errorTips string
@@ -0,0 +1,153 @@
+package mgboot
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"image"
+	"strings"
+)
+
+type ImageProcessOptions struct {
+	width   int
+	height  int
+	fit     string
+	crop    image.Rectangle
+	format  string
+	quality int
+	orient  bool
+	strip   bool
+}
+
+func NewImageProcessOptions(options map[string]interface{}) *ImageProcessOptions {
+	width := castx.ToInt(firstNonEmpty(options["w"], options["width"]))
+	height := castx.ToInt(firstNonEmpty(options["h"], options["height"]))
+	fit := strings.ToLower(castx.ToString(options["fit"]))
+
+	switch fit {
+	case "cover", "contain", "fill", "inside":
+	default:
+		fit = "cover"
+	}
+
+	var crop image.Rectangle
+
+	if parts := strings.Split(castx.ToString(options["crop"]), ","); len(parts) == 4 {
+		x := castx.ToInt(strings.TrimSpace(parts[0]))
+		y := castx.ToInt(strings.TrimSpace(parts[1]))
+		w := castx.ToInt(strings.TrimSpace(parts[2]))
+		h := castx.ToInt(strings.TrimSpace(parts[3]))
+
+		if x >= 0 && y >= 0 && w > 0 && h > 0 {
+			crop = image.Rect(x, y, x+w, y+h)
+		}
+	}
+
+	format := strings.ToLower(castx.ToString(firstNonEmpty(options["fmt"], options["format"])))
+
+	switch format {
+	case "jpg":
+		format = "jpeg"
+	case "jpeg", "png", "gif", "webp":
+	default:
+		format = ""
+	}
+
+	quality := castx.ToInt(firstNonEmpty(options["q"], options["quality"]))
+
+	if quality < 1 || quality > 100 {
+		quality = 0
+	}
+
+	orient := true
+
+	if b1, err := castx.ToBoolE(options["orient"]); err == nil {
+		orient = b1
+	}
+
+	return &ImageProcessOptions{
+		width:   maxInt(width, 0),
+		height:  maxInt(height, 0),
+		fit:     fit,
+		crop:    crop,
+		format:  format,
+		quality: quality,
+		orient:  orient,
+		strip:   castx.ToBool(options["strip"]),
+	}
+}
+
+func ImageProcessOptionsFromCtx(ctx *fiber.Ctx) *ImageProcessOptions {
+	options := map[string]interface{}{}
+
+	for _, key := range []string{"w", "h", "fit", "crop", "fmt", "q", "strip"} {
+		if s1 := ctx.Query(key); s1 != "" {
+			options[key] = s1
+		}
+	}
+
+	return NewImageProcessOptions(options)
+}
+
+func (o *ImageProcessOptions) Width() int {
+	return o.width
+}
+
+func (o *ImageProcessOptions) Height() int {
+	return o.height
+}
+
+func (o *ImageProcessOptions) Fit() string {
+	return o.fit
+}
+
+func (o *ImageProcessOptions) Crop() image.Rectangle {
+	return o.crop
+}
+
+func (o *ImageProcessOptions) Format() string {
+	return o.format
+}
+
+func (o *ImageProcessOptions) Quality() int {
+	return o.quality
+}
+
+func (o *ImageProcessOptions) IsEmpty() bool {
+	return o.width < 1 && o.height < 1 && o.crop.Empty() && o.format == "" && o.quality < 1 && !o.strip
+}
+
+func (o *ImageProcessOptions) CacheKey() string {
+	return fmt.Sprintf(
+		"w%d.h%d.%s.c%d_%d_%d_%d.%s.q%d.o%t.s%t",
+		o.width,
+		o.height,
+		o.fit,
+		o.crop.Min.X,
+		o.crop.Min.Y,
+		o.crop.Dx(),
+		o.crop.Dy(),
+		o.format,
+		o.quality,
+		o.orient,
+		o.strip,
+	)
+}
+
+func firstNonEmpty(values ...interface{}) interface{} {
+	for _, value := range values {
+		if castx.ToString(value) != "" {
+			return value
+		}
+	}
+
+	return nil
+}
+
+func maxInt(n1, n2 int) int {
+	if n1 > n2 {
+		return n1
+	}
+
+	return n2
+}
@@ -0,0 +1,80 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"time"
+)
+
+type ImageProcessSettings struct {
+	maxWidth   int
+	maxHeight  int
+	maxPixels  int
+	quality    int
+	cacheStore string
+	cacheTtl   time.Duration
+}
+
+func NewImageProcessSettings(settings map[string]interface{}) *ImageProcessSettings {
+	maxWidth := 4096
+
+	if n1, err := castx.ToIntE(settings["maxWidth"]); err == nil && n1 > 0 {
+		maxWidth = n1
+	}
+
+	maxHeight := 4096
+
+	if n1, err := castx.ToIntE(settings["maxHeight"]); err == nil && n1 > 0 {
+		maxHeight = n1
+	}
+
+	maxPixels := 50000000
+
+	if n1, err := castx.ToIntE(settings["maxPixels"]); err == nil && n1 > 0 {
+		maxPixels = n1
+	}
+
+	quality := 85
+
+	if n1, err := castx.ToIntE(settings["quality"]); err == nil && n1 > 0 && n1 <= 100 {
+		quality = n1
+	}
+
+	cacheTtl := 24 * time.Hour
+
+	if d1, err := castx.ToDurationE(settings["cacheTtl"]); err == nil && d1 > 0 {
+		cacheTtl = d1
+	}
+
+	return &ImageProcessSettings{
+		maxWidth:   maxWidth,
+		maxHeight:  maxHeight,
+		maxPixels:  maxPixels,
+		quality:    quality,
+		cacheStore: castx.ToString(settings["cacheStore"]),
+		cacheTtl:   cacheTtl,
+	}
+}
+
+func (st *ImageProcessSettings) MaxWidth() int {
+	return st.maxWidth
+}
+
+func (st *ImageProcessSettings) MaxHeight() int {
+	return st.maxHeight
+}
+
+func (st *ImageProcessSettings) MaxPixels() int {
+	return st.maxPixels
+}
+
+func (st *ImageProcessSettings) Quality() int {
+	return st.quality
+}
+
+func (st *ImageProcessSettings) CacheStore() string {
+	return st.cacheStore
+}
+
+func (st *ImageProcessSettings) CacheTtl() time.Duration {
+	return st.cacheTtl
+}
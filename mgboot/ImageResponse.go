@@ -1,6 +1,7 @@
 package mgboot
 
 import (
+	"github.com/gofiber/fiber/v2"
 	"github.com/meiguonet/mgboot-go-common/util/mimex"
 	"io/ioutil"
 )
@@ -60,3 +61,42 @@ func (p ImageResponse) GetContents() (int, string) {
 func (p ImageResponse) Buffer() []byte {
 	return p.buf
 }
+
+// @param arg0 string|[]byte, image file path or image data
+func NewProcessedImageResponse(ctx *fiber.Ctx, arg0 interface{}, opts ...*ImageProcessOptions) ImageResponse {
+	var buf []byte
+
+	if fpath, ok := arg0.(string); ok && fpath != "" {
+		buf, _ = ioutil.ReadFile(fpath)
+	} else if b1, ok := arg0.([]byte); ok {
+		buf = b1
+	}
+
+	var _opts *ImageProcessOptions
+
+	if len(opts) > 0 && opts[0] != nil {
+		_opts = opts[0]
+	} else {
+		_opts = ImageProcessOptionsFromCtx(ctx)
+	}
+
+	return NewImageResponseFromBuffer(buf).Process(_opts)
+}
+
+func (p ImageResponse) Process(opts *ImageProcessOptions) ImageResponse {
+	if len(p.buf) < 1 || opts == nil {
+		return p
+	}
+
+	buf, mimeType, err := ProcessImageCached(p.buf, opts)
+
+	if err != nil {
+		RuntimeLogger().Error(err)
+		return ImageResponse{}
+	}
+
+	return ImageResponse{
+		buf:      buf,
+		mimeType: mimeType,
+	}
+}
@@ -0,0 +1,103 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"net"
+	"strings"
+)
+
+type IpFilterRules struct {
+	allow          []*net.IPNet
+	deny           []*net.IPNet
+	allowCountries []string
+	denyCountries  []string
+}
+
+func NewIpFilterRules(settings map[string]interface{}) *IpFilterRules {
+	return &IpFilterRules{
+		allow:          toIpNets(castx.ToStringSlice(settings["allow"])),
+		deny:           toIpNets(castx.ToStringSlice(settings["deny"])),
+		allowCountries: toUpperSlice(castx.ToStringSlice(settings["allowCountries"])),
+		denyCountries:  toUpperSlice(castx.ToStringSlice(settings["denyCountries"])),
+	}
+}
+
+func (r *IpFilterRules) Allow() []*net.IPNet {
+	return r.allow
+}
+
+func (r *IpFilterRules) Deny() []*net.IPNet {
+	return r.deny
+}
+
+func (r *IpFilterRules) AllowCountries() []string {
+	return r.allowCountries
+}
+
+func (r *IpFilterRules) DenyCountries() []string {
+	return r.denyCountries
+}
+
+func (r *IpFilterRules) IsAllowed(ip net.IP) bool {
+	if ip == nil {
+		return len(r.allow) < 1 && len(r.allowCountries) < 1
+	}
+
+	for _, ipNet := range r.deny {
+		if ipNet.Contains(ip) {
+			return false
+		}
+	}
+
+	for _, ipNet := range r.allow {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	if len(r.allowCountries) > 0 || len(r.denyCountries) > 0 {
+		country := LookupCountry(ip)
+
+		for _, s1 := range r.denyCountries {
+			if s1 == country {
+				return false
+			}
+		}
+
+		if len(r.allowCountries) > 0 {
+			for _, s1 := range r.allowCountries {
+				if s1 == country {
+					return true
+				}
+			}
+
+			return false
+		}
+	}
+
+	return len(r.allow) < 1
+}
+
+func toIpNets(list []string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(list))
+
+	for _, s1 := range list {
+		if ipNet := ParseIpNet(s1); ipNet != nil {
+			nets = append(nets, ipNet)
+		}
+	}
+
+	return nets
+}
+
+func toUpperSlice(list []string) []string {
+	items := make([]string, 0, len(list))
+
+	for _, s1 := range list {
+		if s1 = strings.ToUpper(strings.TrimSpace(s1)); s1 != "" {
+			items = append(items, s1)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,243 @@
+package mgboot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type LocalStorage struct {
+	root     string
+	baseUrl  string
+	signKey  string
+	fileMode os.FileMode
+}
+
+// NewLocalStorage supported keys: root, baseUrl, signKey, fileMode (octal string like "0640", default 0644)
+func NewLocalStorage(settings map[string]interface{}) *LocalStorage {
+	root := castx.ToString(settings["root"])
+
+	if root == "" {
+		root = filepath.Join(os.TempDir(), "mgboot-storage")
+	}
+
+	var fileMode os.FileMode = 0644
+
+	if n1, err := strconv.ParseUint(castx.ToString(settings["fileMode"]), 8, 32); err == nil && n1 > 0 {
+		fileMode = os.FileMode(n1) & os.ModePerm
+	}
+
+	return &LocalStorage{
+		root:     root,
+		baseUrl:  strings.TrimRight(castx.ToString(settings["baseUrl"]), "/"),
+		signKey:  castx.ToString(settings["signKey"]),
+		fileMode: fileMode,
+	}
+}
+
+func (s *LocalStorage) Root() string {
+	return s.root
+}
+
+func (s *LocalStorage) Put(key string, r io.Reader, _ ...map[string]interface{}) error {
+	fpath, err := s.resolvePath(key)
+
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(fpath), ".put-*")
+
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	// ioutil.TempFile creates the file with 0600
+	if err = os.Chmod(f.Name(), s.fileMode); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err = os.Rename(f.Name(), fpath); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return nil
+}
+
+func (s *LocalStorage) Get(key string) (io.ReadCloser, error) {
+	fpath, err := s.resolvePath(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(fpath)
+
+	if os.IsNotExist(err) {
+		return nil, ErrStorageObjectNotFound
+	}
+
+	return f, err
+}
+
+func (s *LocalStorage) Delete(key string) error {
+	fpath, err := s.resolvePath(key)
+
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(fpath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *LocalStorage) Stat(key string) (*StorageObject, error) {
+	fpath, err := s.resolvePath(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(fpath)
+
+	if os.IsNotExist(err) || (err == nil && info.IsDir()) {
+		return nil, ErrStorageObjectNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toStorageObject(key, info), nil
+}
+
+func (s *LocalStorage) List(prefix string) ([]*StorageObject, error) {
+	prefix = strings.TrimLeft(prefix, "/")
+	objects := make([]*StorageObject, 0)
+
+	err := filepath.Walk(s.root, func(fpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".put-") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.root, fpath)
+
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, s.toStorageObject(key, info))
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].key < objects[j].key
+	})
+
+	return objects, nil
+}
+
+func (s *LocalStorage) SignedURL(key string, ttl time.Duration) (string, error) {
+	if _, err := s.resolvePath(key); err != nil {
+		return "", err
+	}
+
+	if s.baseUrl == "" {
+		return "", errors.New("local storage baseUrl not configured")
+	}
+
+	key = strings.TrimLeft(key, "/")
+	u := s.baseUrl + "/" + (&url.URL{Path: key}).EscapedPath()
+
+	if s.signKey == "" {
+		return u, nil
+	}
+
+	expires := fmt.Sprintf("%d", time.Now().Add(ttl).Unix())
+	return u + "?expires=" + expires + "&signature=" + s.sign(key, expires), nil
+}
+
+func (s *LocalStorage) VerifySignature(key, expires, signature string) bool {
+	if s.signKey == "" {
+		return true
+	}
+
+	if castx.ToInt64(expires) < time.Now().Unix() {
+		return false
+	}
+
+	return hmac.Equal([]byte(s.sign(strings.TrimLeft(key, "/"), expires)), []byte(signature))
+}
+
+func (s *LocalStorage) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, []byte(s.signKey))
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalStorage) resolvePath(key string) (string, error) {
+	key = strings.TrimLeft(strings.ReplaceAll(key, "\\", "/"), "/")
+	cleaned := path.Clean("/" + key)[1:]
+
+	if key == "" || cleaned != key {
+		return "", fmt.Errorf("invalid storage key: %s", key)
+	}
+
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), nil
+}
+
+func (s *LocalStorage) toStorageObject(key string, info os.FileInfo) *StorageObject {
+	etag := fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size())
+	return NewStorageObject(key, info.Size(), mime.TypeByExtension(path.Ext(key)), etag, info.ModTime())
+}
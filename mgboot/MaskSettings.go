@@ -0,0 +1,161 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"regexp"
+	"strings"
+)
+
+type MaskSettings struct {
+	fields      []string
+	paths       [][]string
+	detectors   []string
+	headers     []string
+	maxBodySize int64
+	mask        string
+	patterns    []*regexp.Regexp
+}
+
+// NewMaskSettings supported keys: fields (field names or json paths like user.password, items.*.cardNo),
+// detectors (phone|idcard|bankcard|email or custom), headers, maxBodySize, mask
+func NewMaskSettings(settings map[string]interface{}) *MaskSettings {
+	fields := []string{
+		"password",
+		"passwd",
+		"pwd",
+		"secret",
+		"token",
+		"accessToken",
+		"refreshToken",
+		"apiKey",
+		"privateKey",
+	}
+
+	if a1 := castx.ToStringSlice(settings["fields"]); len(a1) > 0 {
+		fields = a1
+	}
+
+	names := make([]string, 0, len(fields))
+	paths := make([][]string, 0)
+
+	for _, s1 := range fields {
+		s1 = strings.TrimPrefix(strings.TrimSpace(s1), "$.")
+
+		if s1 == "" {
+			continue
+		}
+
+		if strings.Contains(s1, ".") {
+			paths = append(paths, strings.Split(strings.ToLower(s1), "."))
+			continue
+		}
+
+		names = append(names, strings.ToLower(s1))
+	}
+
+	detectors := []string{"phone", "idcard", "bankcard", "email"}
+
+	if a1, err := castx.ToStringSliceE(settings["detectors"]); err == nil && settings["detectors"] != nil {
+		detectors = a1
+	}
+
+	headers := []string{
+		"Authorization",
+		"Proxy-Authorization",
+		"Cookie",
+		"Set-Cookie",
+		"X-Api-Key",
+	}
+
+	if a1 := castx.ToStringSlice(settings["headers"]); len(a1) > 0 {
+		headers = a1
+	}
+
+	maxBodySize := toDataSizeOrDefault(settings["maxBodySize"], 4*1024)
+	mask := "******"
+
+	if s1 := castx.ToString(settings["mask"]); s1 != "" {
+		mask = s1
+	}
+
+	patterns := make([]*regexp.Regexp, 0, len(names)*2)
+
+	for _, name := range names {
+		quoted := regexp.QuoteMeta(name)
+		patterns = append(patterns, regexp.MustCompile(`(?i)(<`+quoted+`(?:\s[^>]*)?>)[^<]*(</`+quoted+`>)`))
+		patterns = append(patterns, regexp.MustCompile(`(?i)("?`+quoted+`"?\s*[:=]\s*"?)[^"&,;\s}<]*`))
+	}
+
+	return &MaskSettings{
+		fields:      names,
+		paths:       paths,
+		detectors:   detectors,
+		headers:     headers,
+		maxBodySize: maxBodySize,
+		mask:        mask,
+		patterns:    patterns,
+	}
+}
+
+func (st *MaskSettings) Fields() []string {
+	return st.fields
+}
+
+func (st *MaskSettings) Detectors() []string {
+	return st.detectors
+}
+
+func (st *MaskSettings) Headers() []string {
+	return st.headers
+}
+
+func (st *MaskSettings) MaxBodySize() int64 {
+	return st.maxBodySize
+}
+
+func (st *MaskSettings) Mask() string {
+	return st.mask
+}
+
+func (st *MaskSettings) IsMaskedHeader(name string) bool {
+	for _, s1 := range st.headers {
+		if strings.EqualFold(s1, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsMaskedField reports whether the key at path should be replaced entirely,
+// path holds the lower-cased keys from the document root, array indexes included
+func (st *MaskSettings) IsMaskedField(key string, path []string) bool {
+	key = strings.ToLower(key)
+
+	for _, s1 := range st.fields {
+		if s1 == key {
+			return true
+		}
+	}
+
+	for _, p := range st.paths {
+		if len(p) != len(path) {
+			continue
+		}
+
+		matched := true
+
+		for i, seg := range p {
+			if seg != "*" && seg != path[i] {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,38 @@
+package mgboot
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"time"
+)
+
+func MidAccessLog(settings ...*AccessLogSettings) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidAccessLog")
+		}
+
+		if !AccessLogEnabled() {
+			return ctx.Next()
+		}
+
+		var st *AccessLogSettings
+
+		if len(settings) > 0 && settings[0] != nil {
+			st = settings[0]
+		} else {
+			st = GetAccessLogSettings()
+		}
+
+		if st.IsSkipped(ctx.Path()) {
+			return ctx.Next()
+		}
+
+		ctx.Locals("AccessLogged", true)
+		start := time.Now()
+		err := ctx.Next()
+		entry := BuildAccessLogEntry(ctx, start, err, st)
+		AccessLogLogger().Info(FormatAccessLog(entry, st))
+		return err
+	}
+}
@@ -0,0 +1,61 @@
+package mgboot
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"io/ioutil"
+)
+
+// @param limit int|int64|string, eg: 2097152, "2M"
+func MidBodyLimit(limit interface{}) fiber.Handler {
+	maxBodySize := toDataSizeOrDefault(limit, 0)
+
+	return func(ctx *fiber.Ctx) error {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidBodyLimit")
+		}
+
+		if maxBodySize < 1 {
+			return ctx.Next()
+		}
+
+		ctx.Locals("BodyLimit", maxBodySize)
+		contentLength := int64(ctx.Request().Header.ContentLength())
+
+		if contentLength > maxBodySize {
+			return SendOutput(ctx, nil, NewBodyLimitError("maxBodySize", maxBodySize))
+		}
+
+		if !ctx.Request().IsBodyStream() {
+			if contentLength < 0 && int64(len(ctx.Body())) > maxBodySize {
+				return SendOutput(ctx, nil, NewBodyLimitError("maxBodySize", maxBodySize))
+			}
+
+			return ctx.Next()
+		}
+
+		lr := &limitedUploadReader{
+			r:         ctx.Context().RequestBodyStream(),
+			remaining: maxBodySize,
+			err:       NewBodyLimitError("maxBodySize", maxBodySize),
+		}
+
+		// the multipart uploads keep streaming, the other bodies are read here so that
+		// exceeding the limit is reported instead of being left to ctx.Body()
+		if len(ctx.Request().Header.MultipartFormBoundary()) > 0 {
+			ctx.Request().SetBodyStream(lr, int(contentLength))
+			return ctx.Next()
+		}
+
+		buf, err := ioutil.ReadAll(lr)
+
+		if err != nil {
+			// the rest of the body is left unread on the connection
+			ctx.Context().SetConnectionClose()
+			return SendOutput(ctx, nil, err)
+		}
+
+		ctx.Request().SetBody(buf)
+		return ctx.Next()
+	}
+}
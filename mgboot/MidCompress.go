@@ -0,0 +1,84 @@
+package mgboot
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+)
+
+func MidCompress(settings ...*CompressSettings) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidCompress")
+		}
+
+		if err := ctx.Next(); err != nil {
+			return err
+		}
+
+		if !ctx.Request().Header.IsGet() && !ctx.Request().Header.IsHead() && !ctx.Request().Header.IsPost() {
+			return nil
+		}
+
+		switch ctx.Locals("ResponsePayload").(type) {
+		case JsonResponse, HtmlResponse, XmlResponse, TemplateResponse:
+		default:
+			return nil
+		}
+
+		resp := ctx.Response()
+
+		if resp.StatusCode() != fiber.StatusOK || len(resp.Header.Peek(fiber.HeaderContentEncoding)) > 0 {
+			return nil
+		}
+
+		var st *CompressSettings
+
+		if len(settings) > 0 && settings[0] != nil {
+			st = settings[0]
+		} else {
+			st = GetCompressSettings()
+		}
+
+		body := resp.Body()
+		compressible := st.CompressEnabled() && int64(len(body)) >= st.MinSize() && st.IsCompressible(string(resp.Header.ContentType()))
+		var encoding string
+
+		if compressible {
+			ctx.Vary(fiber.HeaderAcceptEncoding)
+			encoding = negotiateEncoding(ctx.Get(fiber.HeaderAcceptEncoding), st.Encodings())
+		}
+
+		// a conditional request only makes sense for the safe methods, a POST must always get its response,
+		// the etag is weak when the body is going to be compressed so that a 304 carries the same validator
+		if st.EtagEnabled() && (ctx.Request().Header.IsGet() || ctx.Request().Header.IsHead()) {
+			etag := BuildEtag(body, st.WeakEtag())
+
+			if encoding != "" && !st.WeakEtag() {
+				etag = "W/" + etag
+			}
+
+			ctx.Set(fiber.HeaderETag, etag)
+
+			if etagMatch(ctx.Get(fiber.HeaderIfNoneMatch), etag) {
+				ctx.Status(fiber.StatusNotModified)
+				resp.ResetBody()
+				return nil
+			}
+		}
+
+		if encoding == "" {
+			return nil
+		}
+
+		buf, err := compressBytes(encoding, utils.CopyBytes(body), st.Level())
+
+		if err != nil || len(buf) >= len(body) {
+			return nil
+		}
+
+		resp.SetBodyRaw(buf)
+		ctx.Set(fiber.HeaderContentEncoding, encoding)
+		return nil
+	}
+}
@@ -0,0 +1,25 @@
+package mgboot
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"net"
+)
+
+func MidIpFilter(ruleSetName string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidIpFilter")
+		}
+
+		rules := GetIpFilterRules(ruleSetName)
+		clientIp := GetClientIp(ctx)
+
+		// an undefined rule set denies every request rather than leaving the routes open
+		if rules == nil || !rules.IsAllowed(normalizeIp(net.ParseIP(clientIp))) {
+			return SendOutput(ctx, nil, NewAccessDeniedError(clientIp, ruleSetName))
+		}
+
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,25 @@
+package mgboot
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"time"
+)
+
+func MidMetrics() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidMetrics")
+		}
+
+		if !MetricsEnabled() {
+			return ctx.Next()
+		}
+
+		start := time.Now()
+		err := ctx.Next()
+		statusCode := resolveStatusCode(ctx, err)
+		observeHttpRequest(ctx.Method(), resolveRoutePath(ctx, statusCode), statusCode, time.Since(start).Seconds())
+		return err
+	}
+}
@@ -0,0 +1,25 @@
+package mgboot
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+)
+
+func MidRequestId() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidRequestId")
+		}
+
+		tc := GetTraceContext(ctx)
+
+		if tc == nil {
+			tc = resolveTraceContext(ctx)
+			ctx.Locals("TraceContext", tc)
+		}
+
+		ctx.Set(requestIdHeader, tc.RequestId())
+		ctx.Set("traceparent", tc.Traceparent())
+		return ctx.Next()
+	}
+}
@@ -3,6 +3,7 @@ package mgboot
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
 	"strings"
 	"time"
 )
@@ -13,29 +14,42 @@ func MidRequestLog() fiber.Handler {
 			RuntimeLogger().Info("middleware run: mgboot.MidRequestLog")
 		}
 
-		ctx.Locals("ExecStart", time.Now())
+		start := time.Now()
+		ctx.Locals("ExecStart", start)
+		logger := NewContextLogger(RequestLogLogger(), ctx)
+
+		if RequestLogEnabled() {
+			// the request line is part of the access log entry when MidAccessLog runs before
+			if !IsAccessLogged(ctx) {
+				sb := strings.Builder{}
+				sb.WriteString(ctx.Method())
+				sb.WriteString(" ")
+				sb.WriteString(GetRequestUrl(ctx, true))
+				sb.WriteString(" from ")
+				sb.WriteString(GetClientIp(ctx))
+				logger.Info(sb.String())
+			}
 
-		if !RequestLogEnabled() {
-			return ctx.Next()
-		}
+			if LogRequestHeaders() {
+				logger.Debug("request headers: ", jsonx.ToJson(MaskRequestHeaders(ctx)))
+			}
 
-		logger := RequestLogLogger()
-		sb := strings.Builder{}
-		sb.WriteString(ctx.Method())
-		sb.WriteString(" ")
-		sb.WriteString(GetRequestUrl(ctx, true))
-		sb.WriteString(" from ")
-		sb.WriteString(GetClientIp(ctx))
-		logger.Info(sb.String())
+			if LogRequestBody() {
+				if contents := MaskRequestBody(ctx); contents != "" {
+					logger.Debug(contents)
+				}
+			}
+		}
 
-		if LogRequestBody() {
-			rawBody := GetRawBody(ctx)
+		err := ctx.Next()
 
-			if len(rawBody) > 0 {
-				logger.Debugf(string(rawBody))
+		if RequestLogEnabled() && LogResponseBody() {
+			if contents := MaskResponseBody(ctx); contents != "" {
+				logger.Debug("response body: ", contents)
 			}
 		}
 
-		return ctx.Next()
+		LogSlowRequest(ctx, start, err)
+		return err
 	}
 }
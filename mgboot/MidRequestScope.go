@@ -0,0 +1,32 @@
+package mgboot
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+)
+
+// MidRequestScope opens the request scope of the container for the rest of the chain,
+// request scoped services are shared within the request and stopped when it ends
+func MidRequestScope(container ...*Container) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidRequestScope")
+		}
+
+		var c *Container
+
+		if len(container) > 0 && container[0] != nil {
+			c = container[0]
+		} else {
+			c = DefaultContainer()
+		}
+
+		scope, created := c.requestScope(ctx)
+
+		if created {
+			defer c.releaseRequestScope(ctx, scope)
+		}
+
+		return ctx.Next()
+	}
+}
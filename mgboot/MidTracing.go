@@ -0,0 +1,81 @@
+package mgboot
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func MidTracing() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidTracing")
+		}
+
+		if !TracingEnabled() {
+			return ctx.Next()
+		}
+
+		tc := GetTraceContext(ctx)
+
+		if tc == nil {
+			tc = resolveTraceContext(ctx)
+		}
+
+		parent := context.Background()
+
+		if tc.parentSpanId != "" {
+			sc := (&TraceContext{traceId: tc.traceId, spanId: tc.parentSpanId, flags: tc.flags, traceState: tc.traceState}).SpanContext()
+			parent = trace.ContextWithRemoteSpanContext(parent, sc)
+		}
+
+		spanCtx, span := Tracer().Start(
+			parent,
+			ctx.Method()+" "+ctx.Path(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", ctx.Method()),
+				attribute.String("http.target", string(ctx.Request().RequestURI())),
+				attribute.String("http.client_ip", GetClientIp(ctx)),
+				attribute.String("http.request_id", tc.requestId),
+			),
+		)
+
+		defer span.End()
+		requestId := tc.requestId
+
+		if requestId == tc.traceId {
+			requestId = ""
+		}
+
+		newTc := TraceContextFromSpan(span, requestId)
+		newTc.parentSpanId = tc.parentSpanId
+		newTc.traceState = tc.traceState
+		ctx.Locals("TraceContext", newTc)
+		ctx.Locals("SpanContext", spanCtx)
+		ctx.Set(requestIdHeader, newTc.RequestId())
+		ctx.Set("traceparent", newTc.Traceparent())
+
+		err := ctx.Next()
+		statusCode := ctx.Response().StatusCode()
+
+		if route := ctx.Route(); route != nil && route.Path != "" {
+			span.SetName(ctx.Method() + " " + route.Path)
+			span.SetAttributes(attribute.String("http.route", route.Path))
+		}
+
+		span.SetAttributes(attribute.Int("http.status_code", statusCode))
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else if statusCode >= 500 {
+			span.SetStatus(codes.Error, "")
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,354 @@
+package mgboot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"net"
+)
+
+var mmdbMetadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+type MmdbReader struct {
+	buf           []byte
+	nodeCount     uint
+	recordSize    uint
+	ipVersion     uint
+	databaseType  string
+	dataOffset    uint
+	ipv4StartNode uint
+}
+
+func NewMmdbReader(fpath string) (*MmdbReader, error) {
+	buf, err := ioutil.ReadFile(fpath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMmdbReaderFromBytes(buf)
+}
+
+func NewMmdbReaderFromBytes(buf []byte) (*MmdbReader, error) {
+	idx := bytes.LastIndex(buf, mmdbMetadataMarker)
+
+	if idx < 0 {
+		return nil, errors.New("invalid mmdb file: metadata marker not found")
+	}
+
+	metaStart := idx + len(mmdbMetadataMarker)
+	decoder := &mmdbDecoder{buf: buf[metaStart:]}
+	value, _, err := decoder.decode(0)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid mmdb metadata: %s", err.Error())
+	}
+
+	metadata, ok := value.(map[string]interface{})
+
+	if !ok {
+		return nil, errors.New("invalid mmdb metadata")
+	}
+
+	r := &MmdbReader{
+		buf:          buf,
+		nodeCount:    uint(castx.ToInt64(metadata["node_count"])),
+		recordSize:   uint(castx.ToInt64(metadata["record_size"])),
+		ipVersion:    uint(castx.ToInt64(metadata["ip_version"])),
+		databaseType: castx.ToString(metadata["database_type"]),
+	}
+
+	switch r.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("unsupported mmdb record size: %d", r.recordSize)
+	}
+
+	treeSize := r.nodeCount * r.recordSize / 4
+	r.dataOffset = treeSize + 16
+
+	if r.dataOffset > uint(idx) {
+		return nil, errors.New("invalid mmdb file: search tree out of range")
+	}
+
+	if r.ipVersion == 6 {
+		node := uint(0)
+
+		for i := 0; i < 96 && node < r.nodeCount; i++ {
+			node = r.readRecord(node, 0)
+		}
+
+		r.ipv4StartNode = node
+	}
+
+	return r, nil
+}
+
+func (r *MmdbReader) DatabaseType() string {
+	return r.databaseType
+}
+
+func (r *MmdbReader) Lookup(ip net.IP) (map[string]interface{}, error) {
+	if ip == nil {
+		return nil, errors.New("invalid ip address")
+	}
+
+	node := uint(0)
+	bitCount := 128
+
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		bitCount = 32
+
+		if r.ipVersion == 6 {
+			node = r.ipv4StartNode
+		}
+	} else if r.ipVersion == 4 {
+		return nil, errors.New("ipv6 address lookup in an ipv4-only database")
+	}
+
+	for i := 0; i < bitCount && node < r.nodeCount; i++ {
+		bit := uint(ip[i>>3]>>(7-uint(i%8))) & 1
+		node = r.readRecord(node, bit)
+	}
+
+	if node == r.nodeCount {
+		return nil, nil
+	}
+
+	if node < r.nodeCount {
+		return nil, errors.New("invalid mmdb search tree")
+	}
+
+	offset := node - r.nodeCount - 16
+	decoder := &mmdbDecoder{buf: r.buf[r.dataOffset:]}
+	value, _, err := decoder.decode(offset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	record, _ := value.(map[string]interface{})
+	return record, nil
+}
+
+func (r *MmdbReader) LookupCountry(ip net.IP) (string, error) {
+	record, err := r.Lookup(ip)
+
+	if err != nil || record == nil {
+		return "", err
+	}
+
+	for _, key := range []string{"country", "registered_country"} {
+		if map1, ok := record[key].(map[string]interface{}); ok {
+			if s1 := castx.ToString(map1["iso_code"]); s1 != "" {
+				return s1, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+func (r *MmdbReader) readRecord(node, bit uint) uint {
+	size := r.recordSize / 4
+	b := r.buf[node*size : node*size+size]
+
+	switch r.recordSize {
+	case 24:
+		if bit == 0 {
+			return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+
+		return uint(b[3])<<16 | uint(b[4])<<8 | uint(b[5])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		if bit == 0 {
+			return uint(binary.BigEndian.Uint32(b[0:4]))
+		}
+
+		return uint(binary.BigEndian.Uint32(b[4:8]))
+	}
+}
+
+type mmdbDecoder struct {
+	buf []byte
+}
+
+func (d *mmdbDecoder) decode(offset uint) (interface{}, uint, error) {
+	if offset >= uint(len(d.buf)) {
+		return nil, 0, errors.New("unexpected end of mmdb data")
+	}
+
+	ctrl := d.buf[offset]
+	offset++
+	typeNum := uint(ctrl >> 5)
+
+	if typeNum == 1 {
+		pointer, next, err := d.decodePointer(ctrl, offset)
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		value, _, err := d.decode(pointer)
+		return value, next, err
+	}
+
+	if typeNum == 0 {
+		if offset >= uint(len(d.buf)) {
+			return nil, 0, errors.New("unexpected end of mmdb data")
+		}
+
+		typeNum = 7 + uint(d.buf[offset])
+		offset++
+	}
+
+	size := uint(ctrl & 0x1F)
+
+	if size >= 29 {
+		n := size - 28
+
+		if offset+n > uint(len(d.buf)) {
+			return nil, 0, errors.New("unexpected end of mmdb data")
+		}
+
+		extra := uint(0)
+
+		for _, b := range d.buf[offset : offset+n] {
+			extra = extra<<8 | uint(b)
+		}
+
+		switch n {
+		case 1:
+			size = 29 + extra
+		case 2:
+			size = 285 + extra
+		default:
+			size = 65821 + extra
+		}
+
+		offset += n
+	}
+
+	switch typeNum {
+	case 7:
+		map1 := make(map[string]interface{}, size)
+
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decode(offset)
+
+			if err != nil {
+				return nil, 0, err
+			}
+
+			value, next, err := d.decode(next)
+
+			if err != nil {
+				return nil, 0, err
+			}
+
+			map1[castx.ToString(key)] = value
+			offset = next
+		}
+
+		return map1, offset, nil
+	case 11:
+		list := make([]interface{}, 0, size)
+
+		for i := uint(0); i < size; i++ {
+			value, next, err := d.decode(offset)
+
+			if err != nil {
+				return nil, 0, err
+			}
+
+			list = append(list, value)
+			offset = next
+		}
+
+		return list, offset, nil
+	case 14:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > uint(len(d.buf)) {
+		return nil, 0, errors.New("unexpected end of mmdb data")
+	}
+
+	b := d.buf[offset : offset+size]
+	next := offset + size
+
+	switch typeNum {
+	case 2:
+		return string(b), next, nil
+	case 3:
+		if size != 8 {
+			return nil, 0, errors.New("invalid mmdb double size")
+		}
+
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), next, nil
+	case 4:
+		return append([]byte{}, b...), next, nil
+	case 5, 6, 9:
+		var n uint64
+
+		for _, c := range b {
+			n = n<<8 | uint64(c)
+		}
+
+		return n, next, nil
+	case 8:
+		var n uint32
+
+		for _, c := range b {
+			n = n<<8 | uint32(c)
+		}
+
+		return int32(n), next, nil
+	case 10:
+		return new(big.Int).SetBytes(b), next, nil
+	case 15:
+		if size != 4 {
+			return nil, 0, errors.New("invalid mmdb float size")
+		}
+
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), next, nil
+	}
+
+	return nil, 0, fmt.Errorf("unsupported mmdb data type: %d", typeNum)
+}
+
+func (d *mmdbDecoder) decodePointer(ctrl byte, offset uint) (uint, uint, error) {
+	n := uint((ctrl>>3)&0x3) + 1
+
+	if offset+n > uint(len(d.buf)) {
+		return 0, 0, errors.New("unexpected end of mmdb data")
+	}
+
+	b := d.buf[offset : offset+n]
+	var pointer uint
+
+	switch n {
+	case 1:
+		pointer = uint(ctrl&0x7)<<8 | uint(b[0])
+	case 2:
+		pointer = (uint(ctrl&0x7)<<16 | uint(b[0])<<8 | uint(b[1])) + 2048
+	case 3:
+		pointer = (uint(ctrl&0x7)<<24 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])) + 526336
+	default:
+		pointer = uint(binary.BigEndian.Uint32(b))
+	}
+
+	return pointer, offset + n, nil
+}
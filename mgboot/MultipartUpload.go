@@ -0,0 +1,53 @@
+package mgboot
+
+type MultipartUpload struct {
+	fields map[string][]string
+	files  map[string][]*UploadedFile
+	sink   UploadSink
+}
+
+func (u *MultipartUpload) Fields() map[string][]string {
+	return u.fields
+}
+
+func (u *MultipartUpload) Field(name string) string {
+	if values := u.fields[name]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func (u *MultipartUpload) Files() map[string][]*UploadedFile {
+	return u.files
+}
+
+func (u *MultipartUpload) File(fieldName string) *UploadedFile {
+	if files := u.files[fieldName]; len(files) > 0 {
+		return files[0]
+	}
+
+	return nil
+}
+
+func (u *MultipartUpload) FileCount() int {
+	var n int
+
+	for _, files := range u.files {
+		n += len(files)
+	}
+
+	return n
+}
+
+func (u *MultipartUpload) Cleanup() {
+	if u.sink == nil {
+		return
+	}
+
+	for _, files := range u.files {
+		for _, f := range files {
+			_ = u.sink.Remove(f.location)
+		}
+	}
+}
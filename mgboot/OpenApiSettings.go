@@ -0,0 +1,149 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"strings"
+)
+
+type OpenApiSettings struct {
+	title        string
+	version      string
+	description  string
+	servers      []string
+	docPath      string
+	uiPath       string
+	ui           string
+	swaggerUiUrl string
+	redocUrl     string
+	assetsDir    string
+	cdnEnabled   bool
+	integrity    map[string]string
+}
+
+// NewOpenApiSettings supported keys: title, version, description, servers, docPath, uiPath, ui (swagger|redoc),
+// assetsDir (a local copy of swagger-ui-dist or the redoc bundle, the embedded bundles are used otherwise,
+// both are served under uiPath/assets), cdn (load the bundles from swaggerUiUrl and redocUrl instead,
+// pinned to an exact version by default) and integrity (the SRI hashes of the cdn bundles keyed by file name,
+// eg: swagger-ui-bundle.js, swagger-ui.css, redoc.standalone.js)
+func NewOpenApiSettings(settings map[string]interface{}) *OpenApiSettings {
+	title := "API"
+
+	if s1 := strings.TrimSpace(castx.ToString(settings["title"])); s1 != "" {
+		title = s1
+	}
+
+	version := "1.0.0"
+
+	if s1 := strings.TrimSpace(castx.ToString(settings["version"])); s1 != "" {
+		version = s1
+	}
+
+	servers := make([]string, 0)
+
+	for _, s1 := range castx.ToStringSlice(settings["servers"]) {
+		if s1 = strings.TrimSpace(s1); s1 != "" {
+			servers = append(servers, s1)
+		}
+	}
+
+	docPath := "/openapi.json"
+
+	if s1 := strings.TrimSpace(castx.ToString(settings["docPath"])); s1 != "" {
+		docPath = s1
+	}
+
+	uiPath := "/docs"
+
+	if s1 := strings.TrimSpace(castx.ToString(settings["uiPath"])); s1 != "" {
+		uiPath = s1
+	}
+
+	ui := "swagger"
+
+	if strings.ToLower(castx.ToString(settings["ui"])) == "redoc" {
+		ui = "redoc"
+	}
+
+	swaggerUiUrl := "https://unpkg.com/swagger-ui-dist@5.17.14"
+
+	if s1 := strings.TrimSpace(castx.ToString(settings["swaggerUiUrl"])); s1 != "" {
+		swaggerUiUrl = strings.TrimRight(s1, "/")
+	}
+
+	redocUrl := "https://unpkg.com/redoc@2.1.5/bundles/redoc.standalone.js"
+
+	if s1 := strings.TrimSpace(castx.ToString(settings["redocUrl"])); s1 != "" {
+		redocUrl = s1
+	}
+
+	integrity := map[string]string{}
+
+	for name, value := range castx.ToStringMap(settings["integrity"]) {
+		if s1 := strings.TrimSpace(castx.ToString(value)); s1 != "" {
+			integrity[name] = s1
+		}
+	}
+
+	return &OpenApiSettings{
+		title:        title,
+		version:      version,
+		description:  castx.ToString(settings["description"]),
+		servers:      servers,
+		docPath:      docPath,
+		uiPath:       uiPath,
+		ui:           ui,
+		swaggerUiUrl: swaggerUiUrl,
+		redocUrl:     redocUrl,
+		assetsDir:    strings.TrimSpace(castx.ToString(settings["assetsDir"])),
+		cdnEnabled:   castx.ToBool(settings["cdn"]),
+		integrity:    integrity,
+	}
+}
+
+func (st *OpenApiSettings) Title() string {
+	return st.title
+}
+
+func (st *OpenApiSettings) Version() string {
+	return st.version
+}
+
+func (st *OpenApiSettings) Description() string {
+	return st.description
+}
+
+func (st *OpenApiSettings) Servers() []string {
+	return st.servers
+}
+
+func (st *OpenApiSettings) DocPath() string {
+	return st.docPath
+}
+
+func (st *OpenApiSettings) UiPath() string {
+	return st.uiPath
+}
+
+func (st *OpenApiSettings) Ui() string {
+	return st.ui
+}
+
+func (st *OpenApiSettings) SwaggerUiUrl() string {
+	return st.swaggerUiUrl
+}
+
+func (st *OpenApiSettings) RedocUrl() string {
+	return st.redocUrl
+}
+
+func (st *OpenApiSettings) AssetsDir() string {
+	return st.assetsDir
+}
+
+func (st *OpenApiSettings) CdnEnabled() bool {
+	return st.cdnEnabled
+}
+
+func (st *OpenApiSettings) Integrity(fileName string) string {
+	return st.integrity[fileName]
+}
@@ -0,0 +1,22 @@
+package mgboot
+
+import (
+	"fmt"
+	"time"
+)
+
+type RequestTimeoutError struct {
+	timeout time.Duration
+}
+
+func NewRequestTimeoutError(timeout time.Duration) RequestTimeoutError {
+	return RequestTimeoutError{timeout: timeout}
+}
+
+func (ex RequestTimeoutError) Error() string {
+	return fmt.Sprintf("request not completed within %s", ex.timeout)
+}
+
+func (ex RequestTimeoutError) Timeout() time.Duration {
+	return ex.timeout
+}
@@ -0,0 +1,26 @@
+package mgboot
+
+import "github.com/gofiber/fiber/v2"
+
+type requestTimeoutErrorHandler struct {
+}
+
+func NewRequestTimeoutErrorHandler() *requestTimeoutErrorHandler {
+	return &requestTimeoutErrorHandler{}
+}
+
+func (h *requestTimeoutErrorHandler) GetErrorName() string {
+	return "builtin.RequestTimeoutError"
+}
+
+func (h *requestTimeoutErrorHandler) MatchError(err error) bool {
+	if _, ok := err.(RequestTimeoutError); ok {
+		return true
+	}
+
+	return false
+}
+
+func (h *requestTimeoutErrorHandler) HandleError(_ error) ResponsePayload {
+	return NewHttpErrorResponse(fiber.StatusServiceUnavailable)
+}
@@ -0,0 +1,142 @@
+package mgboot
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+type Route struct {
+	method      string
+	path        string
+	name        string
+	handler     fiber.Handler
+	policies    []RoutePolicy
+	summary     string
+	description string
+	tags        []string
+	deprecated  bool
+	input       interface{}
+	output      interface{}
+}
+
+func (r *Route) Method() string {
+	return r.method
+}
+
+func (r *Route) Path() string {
+	return r.path
+}
+
+func (r *Route) Name() string {
+	return r.name
+}
+
+func (r *Route) SetName(name string) *Route {
+	r.name = name
+	return r
+}
+
+// Id identifies the route in rate limiting and logs, the name when set, otherwise "METHOD path"
+func (r *Route) Id() string {
+	if r.name != "" {
+		return r.name
+	}
+
+	return r.method + " " + r.path
+}
+
+func (r *Route) Handler() fiber.Handler {
+	return r.handler
+}
+
+func (r *Route) HandlerName() string {
+	if r.handler == nil {
+		return ""
+	}
+
+	fn := runtime.FuncForPC(reflect.ValueOf(r.handler).Pointer())
+
+	if fn == nil {
+		return ""
+	}
+
+	name := fn.Name()
+
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+
+	return name
+}
+
+func (r *Route) Summary() string {
+	return r.summary
+}
+
+func (r *Route) SetSummary(summary string) *Route {
+	r.summary = summary
+	return r
+}
+
+func (r *Route) Description() string {
+	return r.description
+}
+
+func (r *Route) SetDescription(description string) *Route {
+	r.description = description
+	return r
+}
+
+func (r *Route) Tags() []string {
+	return r.tags
+}
+
+func (r *Route) SetTags(tags ...string) *Route {
+	r.tags = tags
+	return r
+}
+
+func (r *Route) Deprecated() bool {
+	return r.deprecated
+}
+
+func (r *Route) SetDeprecated(flag ...bool) *Route {
+	r.deprecated = len(flag) < 1 || flag[0]
+	return r
+}
+
+func (r *Route) Input() interface{} {
+	return r.input
+}
+
+// SetInput declares the dto filled by mgboot.Bind, only its type is used by the openapi document
+func (r *Route) SetInput(dto interface{}) *Route {
+	r.input = dto
+	return r
+}
+
+func (r *Route) Output() interface{} {
+	return r.output
+}
+
+// SetOutput declares the success payload, only its type is used by the openapi document
+func (r *Route) SetOutput(payload interface{}) *Route {
+	r.output = payload
+	return r
+}
+
+func (r *Route) Policies() []RoutePolicy {
+	return r.policies
+}
+
+func (r *Route) HasPolicy(kind string) bool {
+	for _, p := range r.policies {
+		if p.PolicyKind() == kind {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,71 @@
+package mgboot
+
+import "github.com/gofiber/fiber/v2"
+
+const (
+	PolicyOrderProfile   = 50
+	PolicyOrderCors      = 100
+	PolicyOrderRateLimit = 200
+	PolicyOrderJwtAuth   = 300
+	PolicyOrderValidate  = 400
+	PolicyOrderTimeout   = 500
+)
+
+// RoutePolicy runs before the route handler, policies of a route run by ascending order
+// and a route level policy replaces the group level policy of the same kind
+type RoutePolicy interface {
+	PolicyKind() string
+	PolicyName() string
+	PolicyOrder() int
+	Handler(route *Route) fiber.Handler
+}
+
+type routePolicy struct {
+	kind    string
+	name    string
+	order   int
+	args    []string
+	argsFn  func() []string
+	profile string
+	handler func(route *Route) fiber.Handler
+}
+
+func NewRoutePolicy(name string, order int, handler fiber.Handler) RoutePolicy {
+	return &routePolicy{
+		kind:  name,
+		name:  name,
+		order: order,
+		handler: func(_ *Route) fiber.Handler {
+			return handler
+		},
+	}
+}
+
+func (p *routePolicy) PolicyKind() string {
+	return p.kind
+}
+
+func (p *routePolicy) PolicyName() string {
+	return p.name
+}
+
+func (p *routePolicy) PolicyOrder() int {
+	return p.order
+}
+
+func (p *routePolicy) Handler(route *Route) fiber.Handler {
+	return p.handler(route)
+}
+
+// policyArgs returns the arguments a builtin policy was created with, used by the openapi document
+func policyArgs(p RoutePolicy) []string {
+	if rp, ok := p.(*routePolicy); ok {
+		if rp.argsFn != nil {
+			return rp.argsFn()
+		}
+
+		return rp.args
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package mgboot
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"time"
+)
+
+// ErrorEnvelope reshapes the payload built by the error handlers for the routes of a profile
+type ErrorEnvelope func(ctx *fiber.Ctx, err error, payload ResponsePayload) ResponsePayload
+
+type RouteProfile struct {
+	name        string
+	jwtKey      string
+	corsEnabled bool
+	cors        *CorsSettings
+	rateLimit   map[string]interface{}
+	envelope    ErrorEnvelope
+}
+
+// NewRouteProfile supported keys: jwt (settings key of WithJwtSettings), cors (true for the global settings,
+// or the settings of the profile), rateLimit (total, duration, limitByIp), errorEnvelope (see NewErrorEnvelope)
+func NewRouteProfile(name string, settings map[string]interface{}) *RouteProfile {
+	p := &RouteProfile{
+		name:   name,
+		jwtKey: castx.ToString(settings["jwt"]),
+	}
+
+	if map1 := castx.ToStringMap(settings["cors"]); len(map1) > 0 {
+		p.corsEnabled = true
+		p.cors = NewCorsSettings(map1)
+	} else if castx.ToBool(settings["cors"]) {
+		p.corsEnabled = true
+	}
+
+	if map1 := castx.ToStringMap(settings["rateLimit"]); len(map1) > 0 {
+		var duration time.Duration
+
+		if s1, ok := map1["duration"].(string); ok {
+			duration = castx.ToDuration(s1)
+		} else if n1, err := castx.ToInt64E(map1["duration"]); err == nil {
+			duration = time.Duration(n1) * time.Millisecond
+		}
+
+		total := castx.ToInt(map1["total"])
+
+		if total > 0 && duration > 0 {
+			p.rateLimit = map[string]interface{}{
+				"total":     total,
+				"duration":  duration,
+				"limitByIp": castx.ToBool(map1["limitByIp"]),
+			}
+		}
+	}
+
+	if map1 := castx.ToStringMap(settings["errorEnvelope"]); len(map1) > 0 {
+		p.envelope = NewErrorEnvelope(map1)
+	}
+
+	return p
+}
+
+func (p *RouteProfile) Name() string {
+	return p.name
+}
+
+func (p *RouteProfile) JwtKey() string {
+	return p.jwtKey
+}
+
+func (p *RouteProfile) CorsEnabled() bool {
+	return p.corsEnabled
+}
+
+// Cors returns nil when the profile uses the global cors settings
+func (p *RouteProfile) Cors() *CorsSettings {
+	return p.cors
+}
+
+// RateLimit returns the default rate limit of the routes, replaced by the RateLimit policy of a route
+func (p *RouteProfile) RateLimit() (total int, duration time.Duration, limitByIp bool) {
+	if p.rateLimit == nil {
+		return
+	}
+
+	return p.rateLimit["total"].(int), p.rateLimit["duration"].(time.Duration), p.rateLimit["limitByIp"].(bool)
+}
+
+func (p *RouteProfile) ErrorEnvelope() ErrorEnvelope {
+	return p.envelope
+}
+
+func (p *RouteProfile) SetErrorEnvelope(envelope ErrorEnvelope) *RouteProfile {
+	p.envelope = envelope
+	return p
+}
@@ -0,0 +1,250 @@
+package mgboot
+
+import (
+	"bytes"
+	"github.com/gofiber/fiber/v2"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+type routeTable struct {
+	mu        sync.RWMutex
+	routes    []*Route
+	preflight map[string]bool
+}
+
+type Router struct {
+	app      fiber.Router
+	prefix   string
+	policies []RoutePolicy
+	table    *routeTable
+}
+
+func NewRouter(app fiber.Router, policies ...RoutePolicy) *Router {
+	return &Router{
+		app:      app,
+		policies: expandProfilePolicies(policies),
+		table:    &routeTable{routes: make([]*Route, 0), preflight: map[string]bool{}},
+	}
+}
+
+// Group shares the route table of r, its policies are added to those of r
+func (r *Router) Group(prefix string, policies ...RoutePolicy) *Router {
+	return &Router{
+		app:      r.app,
+		prefix:   joinRoutePath(r.prefix, prefix),
+		policies: mergeRoutePolicies(r.policies, policies),
+		table:    r.table,
+	}
+}
+
+func (r *Router) Prefix() string {
+	return r.prefix
+}
+
+func (r *Router) Get(path string, handler fiber.Handler, policies ...RoutePolicy) *Route {
+	return r.Add(fiber.MethodGet, path, handler, policies...)
+}
+
+func (r *Router) Post(path string, handler fiber.Handler, policies ...RoutePolicy) *Route {
+	return r.Add(fiber.MethodPost, path, handler, policies...)
+}
+
+func (r *Router) Put(path string, handler fiber.Handler, policies ...RoutePolicy) *Route {
+	return r.Add(fiber.MethodPut, path, handler, policies...)
+}
+
+func (r *Router) Patch(path string, handler fiber.Handler, policies ...RoutePolicy) *Route {
+	return r.Add(fiber.MethodPatch, path, handler, policies...)
+}
+
+func (r *Router) Delete(path string, handler fiber.Handler, policies ...RoutePolicy) *Route {
+	return r.Add(fiber.MethodDelete, path, handler, policies...)
+}
+
+func (r *Router) Head(path string, handler fiber.Handler, policies ...RoutePolicy) *Route {
+	return r.Add(fiber.MethodHead, path, handler, policies...)
+}
+
+func (r *Router) Options(path string, handler fiber.Handler, policies ...RoutePolicy) *Route {
+	return r.Add(fiber.MethodOptions, path, handler, policies...)
+}
+
+func (r *Router) Add(method, path string, handler fiber.Handler, policies ...RoutePolicy) *Route {
+	route := &Route{
+		method:   strings.ToUpper(method),
+		path:     joinRoutePath(r.prefix, path),
+		handler:  handler,
+		policies: mergeRoutePolicies(r.policies, policies),
+	}
+
+	handlers := make([]fiber.Handler, 0, len(route.policies)+1)
+
+	for _, p := range route.policies {
+		handlers = append(handlers, p.Handler(route))
+	}
+
+	handlers = append(handlers, handler)
+	r.app.Add(route.method, route.path, handlers...)
+	r.table.mu.Lock()
+	r.table.routes = append(r.table.routes, route)
+	registerPreflight := route.method != fiber.MethodOptions && route.HasPolicy("Cors") && !r.table.preflight[route.path]
+
+	if registerPreflight {
+		r.table.preflight[route.path] = true
+	}
+
+	r.table.mu.Unlock()
+
+	// browsers send the preflight without credentials, only the cors policy answers it
+	if registerPreflight {
+		for _, p := range route.policies {
+			if p.PolicyKind() == "Cors" {
+				r.app.Add(fiber.MethodOptions, route.path, p.Handler(route), func(ctx *fiber.Ctx) error {
+					return ctx.SendStatus(fiber.StatusNoContent)
+				})
+
+				break
+			}
+		}
+	}
+
+	return route
+}
+
+func (r *Router) Routes() []*Route {
+	r.table.mu.RLock()
+	defer r.table.mu.RUnlock()
+	routes := make([]*Route, len(r.table.routes))
+	copy(routes, r.table.routes)
+	return routes
+}
+
+func (r *Router) RouteTable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+	w.Write([]byte("METHOD\tPATH\tNAME\tPOLICIES\tHANDLER\n"))
+
+	for _, route := range r.Routes() {
+		names := make([]string, 0, len(route.Policies()))
+
+		for _, p := range route.Policies() {
+			names = append(names, p.PolicyName())
+		}
+
+		name := route.Name()
+
+		if name == "" {
+			name = "-"
+		}
+
+		policies := strings.Join(names, ", ")
+
+		if policies == "" {
+			policies = "-"
+		}
+
+		w.Write([]byte(strings.Join([]string{route.Method(), route.Path(), name, policies, route.HandlerName()}, "\t") + "\n"))
+	}
+
+	w.Flush()
+	return buf.String()
+}
+
+// RouteTableHandler serves the route table as plain text, meant for debugging
+func (r *Router) RouteTableHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		ctx.Type("txt", "utf8")
+		return ctx.SendString(r.RouteTable())
+	}
+}
+
+// ServeOpenApi registers the openapi document and the swagger ui or redoc page, they are left out of the route table
+func (r *Router) ServeOpenApi(settings ...*OpenApiSettings) {
+	var st *OpenApiSettings
+
+	if len(settings) > 0 && settings[0] != nil {
+		st = settings[0]
+	} else {
+		st = GetOpenApiSettings()
+	}
+
+	docUrl := joinRoutePath(r.prefix, st.DocPath())
+	r.app.Get(docUrl, OpenApiHandler(r, st))
+
+	if st.AssetsDir() != "" {
+		r.app.Static(openApiAssetsUrl(docUrl, st), st.AssetsDir())
+	} else if !st.CdnEnabled() {
+		r.app.Get(openApiAssetsUrl(docUrl, st)+"/:file", OpenApiAssetsHandler())
+	}
+
+	if st.Ui() == "redoc" {
+		r.app.Get(joinRoutePath(r.prefix, st.UiPath()), RedocHandler(docUrl, st))
+		return
+	}
+
+	r.app.Get(joinRoutePath(r.prefix, st.UiPath()), SwaggerUiHandler(docUrl, st))
+}
+
+// ServeHealth registers the liveness and readiness endpoints, they are left out of the route table
+func (r *Router) ServeHealth(settings ...*HealthSettings) {
+	var st *HealthSettings
+
+	if len(settings) > 0 && settings[0] != nil {
+		st = settings[0]
+	} else {
+		st = GetHealthSettings()
+	}
+
+	r.app.Get(joinRoutePath(r.prefix, st.LivePath()), HealthLiveHandler(st))
+	r.app.Get(joinRoutePath(r.prefix, st.ReadyPath()), HealthReadyHandler(st))
+}
+
+func mergeRoutePolicies(base, policies []RoutePolicy) []RoutePolicy {
+	policies = expandProfilePolicies(policies)
+	merged := make([]RoutePolicy, 0, len(base)+len(policies))
+
+	for _, p := range base {
+		overridden := false
+
+		for _, p2 := range policies {
+			if p2.PolicyKind() == p.PolicyKind() {
+				overridden = true
+				break
+			}
+		}
+
+		if !overridden {
+			merged = append(merged, p)
+		}
+	}
+
+	merged = append(merged, policies...)
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].PolicyOrder() < merged[j].PolicyOrder()
+	})
+
+	return merged
+}
+
+func joinRoutePath(prefix, path string) string {
+	prefix = strings.TrimRight(prefix, "/")
+	path = strings.TrimSpace(path)
+
+	if path == "" || path == "/" {
+		if prefix == "" {
+			return "/"
+		}
+
+		return prefix
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	return prefix + path
+}
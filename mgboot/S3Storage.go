@@ -0,0 +1,631 @@
+package mgboot
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// s3PartSize is the minimum size of a multipart upload part except for the last one
+	s3PartSize   = 5 << 20
+	s3MaxPutSize = 5 << 30
+)
+
+var s3EmptyPayloadHash = sha256Hex(nil)
+
+type S3Storage struct {
+	endpoint  string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	pathStyle bool
+	client    *http.Client
+	now       func() time.Time
+}
+
+func NewS3Storage(settings map[string]interface{}) *S3Storage {
+	region := castx.ToString(settings["region"])
+
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	endpoint := strings.TrimRight(castx.ToString(settings["endpoint"]), "/")
+
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+
+	pathStyle := true
+
+	if b1, err := castx.ToBoolE(settings["pathStyle"]); err == nil {
+		pathStyle = b1
+	}
+
+	timeout := 30 * time.Second
+
+	if d1, err := castx.ToDurationE(settings["timeout"]); err == nil && d1 > 0 {
+		timeout = d1
+	}
+
+	return &S3Storage{
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    castx.ToString(settings["bucket"]),
+		accessKey: castx.ToString(settings["accessKey"]),
+		secretKey: castx.ToString(settings["secretKey"]),
+		pathStyle: pathStyle,
+		client:    &http.Client{Timeout: timeout},
+		now:       time.Now,
+	}
+}
+
+func (s *S3Storage) Bucket() string {
+	return s.bucket
+}
+
+// Put streams r with an unsigned payload when its size is known, from opts["size"] or when r is a
+// bytes.Reader, strings.Reader, bytes.Buffer or regular file, otherwise r is sent as a multipart upload
+// of s3PartSize parts so that only one part is held in memory
+func (s *S3Storage) Put(key string, r io.Reader, opts ...map[string]interface{}) error {
+	var _opts map[string]interface{}
+
+	if len(opts) > 0 {
+		_opts = opts[0]
+	}
+
+	contentType := castx.ToString(_opts["contentType"])
+
+	if size := s3ReaderSize(r, _opts); size >= 0 && size <= s3MaxPutSize {
+		return s.putObject(key, r, size, contentType)
+	}
+
+	buf := make([]byte, s3PartSize)
+	n, err := io.ReadFull(r, buf)
+
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return s.putObject(key, bytes.NewReader(buf[:n]), int64(n), contentType)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return s.multipartUpload(key, io.MultiReader(bytes.NewReader(buf), r), contentType)
+}
+
+func (s *S3Storage) Get(key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(http.MethodGet, key, nil, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req, s3EmptyPayloadHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+func (s *S3Storage) Delete(key string) error {
+	req, err := s.newRequest(http.MethodDelete, key, nil, nil)
+
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req, s3EmptyPayloadHash)
+
+	if err == ErrStorageObjectNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Storage) Stat(key string) (*StorageObject, error) {
+	req, err := s.newRequest(http.MethodHead, key, nil, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req, s3EmptyPayloadHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body.Close()
+	lastModified, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+
+	return NewStorageObject(
+		key,
+		resp.ContentLength,
+		resp.Header.Get("Content-Type"),
+		strings.Trim(resp.Header.Get("ETag"), `"`),
+		lastModified,
+	), nil
+}
+
+func (s *S3Storage) List(prefix string) ([]*StorageObject, error) {
+	objects := make([]*StorageObject, 0)
+	var token string
+
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", strings.TrimLeft(prefix, "/"))
+
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		req, err := s.newRequest(http.MethodGet, "", query, nil)
+
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := s.do(req, s3EmptyPayloadHash)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Contents []struct {
+				Key          string
+				Size         int64
+				ETag         string
+				LastModified time.Time
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range result.Contents {
+			objects = append(objects, NewStorageObject(item.Key, item.Size, "", strings.Trim(item.ETag, `"`), item.LastModified))
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+
+		token = result.NextContinuationToken
+	}
+
+	return objects, nil
+}
+
+func (s *S3Storage) SignedURL(key string, ttl time.Duration) (string, error) {
+	u, err := s.objectUrl(key)
+
+	if err != nil {
+		return "", err
+	}
+
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + s.region + "/s3/aws4_request"
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.accessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", fmt.Sprintf("%d", int64(ttl/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	headers := http.Header{}
+	headers.Set("Host", u.Host)
+	canonicalRequest := s.canonicalRequest(http.MethodGet, u.EscapedPath(), query, headers, s3UnsignedPayload)
+	signature := s.sign(now, scope, canonicalRequest)
+	return u.Scheme + "://" + u.Host + u.EscapedPath() + "?" + s.canonicalQuery(query) + "&X-Amz-Signature=" + signature, nil
+}
+
+func (s *S3Storage) newRequest(method, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	u, err := s.objectUrl(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(query) > 0 {
+		u.RawQuery = s.canonicalQuery(query)
+	}
+
+	return http.NewRequest(method, u.String(), body)
+}
+
+func (s *S3Storage) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + s.region + "/s3/aws4_request"
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := http.Header{}
+
+	for _, name := range []string{"Host", "X-Amz-Date", "X-Amz-Content-Sha256", "Content-Type"} {
+		if value := req.Header.Get(name); value != "" {
+			headers.Set(name, value)
+		}
+	}
+
+	canonicalRequest := s.canonicalRequest(req.Method, req.URL.EscapedPath(), req.URL.Query(), headers, payloadHash)
+	signature := s.sign(now, scope, canonicalRequest)
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey,
+		scope,
+		signedHeaderNames(headers),
+		signature,
+	))
+
+	resp, err := s.client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrStorageObjectNotFound
+	}
+
+	if resp.StatusCode >= 300 {
+		buf, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		return nil, fmt.Errorf("s3 request failed, status: %d, response: %s", resp.StatusCode, string(buf))
+	}
+
+	return resp, nil
+}
+
+func (s *S3Storage) putObject(key string, r io.Reader, size int64, contentType string) error {
+	body := io.Reader(http.NoBody)
+
+	if size > 0 {
+		// the http client closes the request body, r belongs to the caller
+		body = ioutil.NopCloser(r)
+	}
+
+	req, err := s.newRequest(http.MethodPut, key, nil, body)
+
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = size
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req, s3UnsignedPayload)
+
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Storage) multipartUpload(key string, r io.Reader, contentType string) error {
+	query := url.Values{}
+	query.Set("uploads", "")
+	req, err := s.newRequest(http.MethodPost, key, query, nil)
+
+	if err != nil {
+		return err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req, s3EmptyPayloadHash)
+
+	if err != nil {
+		return err
+	}
+
+	var initiated struct {
+		UploadId string
+	}
+
+	err = xml.NewDecoder(resp.Body).Decode(&initiated)
+	resp.Body.Close()
+
+	if err != nil {
+		return err
+	}
+
+	if err = s.uploadParts(key, initiated.UploadId, r); err != nil {
+		s.abortMultipartUpload(key, initiated.UploadId)
+		return err
+	}
+
+	return nil
+}
+
+func (s *S3Storage) uploadParts(key, uploadId string, r io.Reader) error {
+	type completedPart struct {
+		PartNumber int
+		ETag       string
+	}
+
+	var completed struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}
+
+	buf := make([]byte, s3PartSize)
+
+	for partNumber := 1; ; partNumber++ {
+		n, err := io.ReadFull(r, buf)
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return err
+		}
+
+		query := url.Values{}
+		query.Set("partNumber", strconv.Itoa(partNumber))
+		query.Set("uploadId", uploadId)
+		req, err1 := s.newRequest(http.MethodPut, key, query, bytes.NewReader(buf[:n]))
+
+		if err1 != nil {
+			return err1
+		}
+
+		resp, err1 := s.do(req, sha256Hex(buf[:n]))
+
+		if err1 != nil {
+			return err1
+		}
+
+		resp.Body.Close()
+		completed.Parts = append(completed.Parts, completedPart{PartNumber: partNumber, ETag: resp.Header.Get("ETag")})
+
+		if err == io.ErrUnexpectedEOF {
+			break
+		}
+	}
+
+	payload, err := xml.Marshal(completed)
+
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("uploadId", uploadId)
+	req, err := s.newRequest(http.MethodPost, key, query, bytes.NewReader(payload))
+
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req, sha256Hex(payload))
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	// CompleteMultipartUpload may fail with status 200 and an Error document
+	var result struct {
+		XMLName xml.Name
+		Code    string
+		Message string
+	}
+
+	if err = xml.NewDecoder(resp.Body).Decode(&result); err == nil && result.XMLName.Local == "Error" {
+		return fmt.Errorf("s3 request failed, code: %s, message: %s", result.Code, result.Message)
+	}
+
+	return nil
+}
+
+func (s *S3Storage) abortMultipartUpload(key, uploadId string) {
+	query := url.Values{}
+	query.Set("uploadId", uploadId)
+	req, err := s.newRequest(http.MethodDelete, key, query, nil)
+
+	if err != nil {
+		return
+	}
+
+	if resp, err := s.do(req, s3EmptyPayloadHash); err == nil {
+		resp.Body.Close()
+	}
+}
+
+func (s *S3Storage) objectUrl(key string) (*url.URL, error) {
+	u, err := url.Parse(s.endpoint)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key = strings.TrimLeft(key, "/")
+
+	if s.pathStyle {
+		u.Path = "/" + s.bucket + "/" + key
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+
+	u.RawPath = s3EscapePath(u.Path)
+	return u, nil
+}
+
+func (s *S3Storage) canonicalRequest(method, path string, query url.Values, headers http.Header, payloadHash string) string {
+	names := make([]string, 0, len(headers))
+
+	for name := range headers {
+		names = append(names, strings.ToLower(name))
+	}
+
+	sort.Strings(names)
+	sb := strings.Builder{}
+
+	for _, name := range names {
+		sb.WriteString(name + ":" + strings.TrimSpace(headers.Get(name)) + "\n")
+	}
+
+	return strings.Join([]string{
+		method,
+		path,
+		s.canonicalQuery(query),
+		sb.String(),
+		strings.Join(names, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+func (s *S3Storage) canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+
+	for key := range query {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, s3Escape(key, true)+"="+s3Escape(value, true))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+func (s *S3Storage) sign(t time.Time, scope, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		t.Format("20060102T150405Z"),
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSha256([]byte("AWS4"+s.secretKey), t.Format("20060102"))
+	key = hmacSha256(key, s.region)
+	key = hmacSha256(key, "s3")
+	key = hmacSha256(key, "aws4_request")
+	return hex.EncodeToString(hmacSha256(key, stringToSign))
+}
+
+func s3ReaderSize(r io.Reader, opts map[string]interface{}) int64 {
+	if opts["size"] != nil {
+		if n1, err := castx.ToInt64E(opts["size"]); err == nil && n1 >= 0 {
+			return n1
+		}
+	}
+
+	switch rd := r.(type) {
+	case interface{ Len() int }:
+		return int64(rd.Len())
+	case *os.File:
+		fi, err := rd.Stat()
+
+		if err != nil || !fi.Mode().IsRegular() {
+			return -1
+		}
+
+		offset, err := rd.Seek(0, io.SeekCurrent)
+
+		if err != nil {
+			return -1
+		}
+
+		return fi.Size() - offset
+	}
+
+	return -1
+}
+
+func signedHeaderNames(headers http.Header) string {
+	names := make([]string, 0, len(headers))
+
+	for name := range headers {
+		names = append(names, strings.ToLower(name))
+	}
+
+	sort.Strings(names)
+	return strings.Join(names, ";")
+}
+
+func s3EscapePath(path string) string {
+	return s3Escape(path, false)
+}
+
+func s3Escape(s1 string, encodeSlash bool) string {
+	sb := strings.Builder{}
+
+	for _, b := range []byte(s1) {
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') || b == '-' || b == '_' || b == '.' || b == '~' {
+			sb.WriteByte(b)
+		} else if b == '/' && !encodeSlash {
+			sb.WriteByte(b)
+		} else {
+			sb.WriteString(fmt.Sprintf("%%%02X", b))
+		}
+	}
+
+	return sb.String()
+}
+
+func sha256Hex(buf []byte) string {
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,56 @@
+package mgboot
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrStorageObjectNotFound = errors.New("storage object not found")
+
+type Storage interface {
+	Put(key string, r io.Reader, opts ...map[string]interface{}) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+	Stat(key string) (*StorageObject, error)
+	List(prefix string) ([]*StorageObject, error)
+	SignedURL(key string, ttl time.Duration) (string, error)
+}
+
+type StorageObject struct {
+	key          string
+	size         int64
+	contentType  string
+	etag         string
+	lastModified time.Time
+}
+
+func NewStorageObject(key string, size int64, contentType, etag string, lastModified time.Time) *StorageObject {
+	return &StorageObject{
+		key:          key,
+		size:         size,
+		contentType:  contentType,
+		etag:         etag,
+		lastModified: lastModified,
+	}
+}
+
+func (o *StorageObject) Key() string {
+	return o.key
+}
+
+func (o *StorageObject) Size() int64 {
+	return o.size
+}
+
+func (o *StorageObject) ContentType() string {
+	return o.contentType
+}
+
+func (o *StorageObject) Etag() string {
+	return o.etag
+}
+
+func (o *StorageObject) LastModified() time.Time {
+	return o.lastModified
+}
@@ -0,0 +1,35 @@
+package mgboot
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/util/errorx"
+)
+
+type TemplateResponse struct {
+	name   string
+	data   interface{}
+	layout []string
+}
+
+func NewTemplateResponse(name string, data interface{}, layout ...string) TemplateResponse {
+	return TemplateResponse{
+		name:   name,
+		data:   data,
+		layout: layout,
+	}
+}
+
+func (p TemplateResponse) GetContentType() string {
+	return fiber.MIMETextHTMLCharsetUTF8
+}
+
+func (p TemplateResponse) GetContents() (int, string) {
+	contents, err := RenderTemplate(p.name, p.data, p.layout...)
+
+	if err != nil {
+		RuntimeLogger().Error(errorx.Stacktrace(err))
+		return 500, ""
+	}
+
+	return 200, contents
+}
@@ -0,0 +1,156 @@
+package mgboot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+type TraceContext struct {
+	requestId    string
+	traceId      string
+	spanId       string
+	parentSpanId string
+	flags        byte
+	traceState   string
+}
+
+func NewTraceContext(requestId ...string) *TraceContext {
+	var _requestId string
+
+	if len(requestId) > 0 {
+		_requestId = requestId[0]
+	}
+
+	traceId := randomHex(16)
+
+	if _requestId == "" {
+		_requestId = traceId
+	}
+
+	return &TraceContext{
+		requestId: _requestId,
+		traceId:   traceId,
+		spanId:    randomHex(8),
+		flags:     0x01,
+	}
+}
+
+// ParseTraceparent parses a W3C traceparent header, eg: 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func ParseTraceparent(traceparent string) *TraceContext {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return nil
+	}
+
+	if parts[0] == "00" && len(parts) != 4 {
+		return nil
+	}
+
+	traceId := strings.ToLower(parts[1])
+	spanId := strings.ToLower(parts[2])
+
+	if !isLowerHex(traceId, 32) || !isLowerHex(spanId, 16) || !isLowerHex(strings.ToLower(parts[3]), 2) {
+		return nil
+	}
+
+	if strings.Trim(traceId, "0") == "" || strings.Trim(spanId, "0") == "" {
+		return nil
+	}
+
+	flags, _ := hex.DecodeString(parts[3])
+
+	return &TraceContext{
+		traceId: traceId,
+		spanId:  spanId,
+		flags:   flags[0],
+	}
+}
+
+func (tc *TraceContext) RequestId() string {
+	return tc.requestId
+}
+
+func (tc *TraceContext) TraceId() string {
+	return tc.traceId
+}
+
+func (tc *TraceContext) SpanId() string {
+	return tc.spanId
+}
+
+func (tc *TraceContext) ParentSpanId() string {
+	return tc.parentSpanId
+}
+
+func (tc *TraceContext) Sampled() bool {
+	return tc.flags&0x01 == 0x01
+}
+
+func (tc *TraceContext) TraceState() string {
+	return tc.traceState
+}
+
+func (tc *TraceContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", tc.traceId, tc.spanId, tc.flags)
+}
+
+// Child returns a new span context within the same trace whose parent is tc.
+func (tc *TraceContext) Child() *TraceContext {
+	return &TraceContext{
+		requestId:    tc.requestId,
+		traceId:      tc.traceId,
+		spanId:       randomHex(8),
+		parentSpanId: tc.spanId,
+		flags:        tc.flags,
+		traceState:   tc.traceState,
+	}
+}
+
+func (tc *TraceContext) Headers() map[string]string {
+	headers := map[string]string{
+		RequestIdHeader(): tc.requestId,
+		"Traceparent":     tc.Traceparent(),
+	}
+
+	if tc.traceState != "" {
+		headers["Tracestate"] = tc.traceState
+	}
+
+	return headers
+}
+
+func (tc *TraceContext) ToMap() map[string]interface{} {
+	map1 := map[string]interface{}{
+		"requestId":   tc.requestId,
+		"traceparent": tc.Traceparent(),
+	}
+
+	if tc.traceState != "" {
+		map1["tracestate"] = tc.traceState
+	}
+
+	return map1
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+func isLowerHex(s1 string, length int) bool {
+	if len(s1) != length {
+		return false
+	}
+
+	for _, c := range s1 {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
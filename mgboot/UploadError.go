@@ -0,0 +1,53 @@
+package mgboot
+
+import (
+	"fmt"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"strings"
+)
+
+type UploadError struct {
+	errno  int
+	field  string
+	params map[string]interface{}
+}
+
+func NewUploadError(errno int, params ...map[string]interface{}) UploadError {
+	_params := map[string]interface{}{}
+
+	if len(params) > 0 && params[0] != nil {
+		_params = params[0]
+	}
+
+	return UploadError{
+		errno:  errno,
+		field:  castx.ToString(_params["field"]),
+		params: _params,
+	}
+}
+
+func (ex UploadError) Error() string {
+	return ex.Message()
+}
+
+func (ex UploadError) Errno() int {
+	return ex.errno
+}
+
+func (ex UploadError) Field() string {
+	return ex.field
+}
+
+func (ex UploadError) Params() map[string]interface{} {
+	return ex.params
+}
+
+func (ex UploadError) Message(locale ...string) string {
+	msg := uploadMessage(ex.errno, locale...)
+
+	for key, value := range ex.params {
+		msg = strings.ReplaceAll(msg, "{"+key+"}", fmt.Sprintf("%v", value))
+	}
+
+	return msg
+}
@@ -0,0 +1,36 @@
+package mgboot
+
+type uploadErrorHandler struct {
+}
+
+func NewUploadErrorHandler() *uploadErrorHandler {
+	return &uploadErrorHandler{}
+}
+
+func (h *uploadErrorHandler) GetErrorName() string {
+	return "builtin.UploadError"
+}
+
+func (h *uploadErrorHandler) MatchError(err error) bool {
+	if _, ok := err.(UploadError); ok {
+		return true
+	}
+
+	return false
+}
+
+func (h *uploadErrorHandler) HandleError(err error) ResponsePayload {
+	ex := err.(UploadError)
+
+	payload := map[string]interface{}{
+		"code": 1006,
+		"msg":  ex.Message(),
+		"data": map[string]interface{}{
+			"errno":  ex.Errno(),
+			"field":  ex.Field(),
+			"params": ex.Params(),
+		},
+	}
+
+	return NewJsonResponse(payload)
+}
@@ -0,0 +1,113 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"os"
+	"strings"
+)
+
+type UploadSettings struct {
+	maxBodySize  int64
+	maxFileSize  int64
+	maxFiles     int
+	maxFieldSize int64
+	maxFields    int
+	tempDir      string
+	checksums    []string
+}
+
+func NewUploadSettings(settings map[string]interface{}) *UploadSettings {
+	maxFiles := 10
+
+	if n1, err := castx.ToIntE(settings["maxFiles"]); err == nil && n1 > 0 {
+		maxFiles = n1
+	}
+
+	maxFields := 1000
+
+	if n1, err := castx.ToIntE(settings["maxFields"]); err == nil && n1 > 0 {
+		maxFields = n1
+	}
+
+	tempDir := castx.ToString(settings["tempDir"])
+
+	if tempDir == "" {
+		tempDir = os.TempDir()
+	}
+
+	checksums := []string{"sha256"}
+
+	if a1, ok := settings["checksums"]; ok {
+		checksums = make([]string, 0)
+
+		for _, s1 := range castx.ToStringSlice(a1) {
+			s1 = strings.ToLower(strings.TrimSpace(s1))
+
+			switch s1 {
+			case "md5", "sha1", "sha256":
+				checksums = append(checksums, s1)
+			}
+		}
+	}
+
+	return &UploadSettings{
+		maxBodySize:  toDataSizeOrDefault(settings["maxBodySize"], 32*1024*1024),
+		maxFileSize:  toDataSizeOrDefault(settings["maxFileSize"], 8*1024*1024),
+		maxFiles:     maxFiles,
+		maxFieldSize: toDataSizeOrDefault(settings["maxFieldSize"], 1024*1024),
+		maxFields:    maxFields,
+		tempDir:      tempDir,
+		checksums:    checksums,
+	}
+}
+
+func (st *UploadSettings) MaxBodySize() int64 {
+	return st.maxBodySize
+}
+
+func (st *UploadSettings) MaxFileSize() int64 {
+	return st.maxFileSize
+}
+
+func (st *UploadSettings) MaxFiles() int {
+	return st.maxFiles
+}
+
+func (st *UploadSettings) MaxFieldSize() int64 {
+	return st.maxFieldSize
+}
+
+func (st *UploadSettings) MaxFields() int {
+	return st.maxFields
+}
+
+func (st *UploadSettings) TempDir() string {
+	return st.tempDir
+}
+
+func (st *UploadSettings) Checksums() []string {
+	return st.checksums
+}
+
+func toDataSizeOrDefault(value interface{}, defaultValue int64) int64 {
+	switch v := value.(type) {
+	case int64:
+		if v > 0 {
+			return v
+		}
+	case int:
+		if v > 0 {
+			return int64(v)
+		}
+	case float64:
+		if v > 0 {
+			return int64(v)
+		}
+	case string:
+		if n1 := castx.ToDataSize(v); n1 > 0 {
+			return n1
+		}
+	}
+
+	return defaultValue
+}
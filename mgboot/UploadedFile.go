@@ -0,0 +1,38 @@
+package mgboot
+
+type UploadedFile struct {
+	fieldName   string
+	fileName    string
+	contentType string
+	size        int64
+	location    string
+	checksums   map[string]string
+}
+
+func (f *UploadedFile) FieldName() string {
+	return f.fieldName
+}
+
+func (f *UploadedFile) FileName() string {
+	return f.fileName
+}
+
+func (f *UploadedFile) ContentType() string {
+	return f.contentType
+}
+
+func (f *UploadedFile) Size() int64 {
+	return f.size
+}
+
+func (f *UploadedFile) Location() string {
+	return f.location
+}
+
+func (f *UploadedFile) Checksum(algo string) string {
+	return f.checksums[algo]
+}
+
+func (f *UploadedFile) Checksums() map[string]string {
+	return f.checksums
+}
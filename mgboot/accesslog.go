@@ -0,0 +1,196 @@
+package mgboot
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/logx"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var accessLogLogger logx.Logger
+var accessLogSettings *AccessLogSettings
+var accessLogPlaceholder = regexp.MustCompile(`\{([A-Za-z]+)}`)
+
+func AccessLogLogger(logger ...logx.Logger) logx.Logger {
+	if len(logger) > 0 {
+		accessLogLogger = logger[0]
+	}
+
+	l := accessLogLogger
+
+	if l == nil {
+		l = NewNoopLogger()
+	}
+
+	return l
+}
+
+func AccessLogEnabled() bool {
+	return accessLogLogger != nil
+}
+
+// IsAccessLogged reports whether MidAccessLog writes the entry of the current request
+func IsAccessLogged(ctx *fiber.Ctx) bool {
+	b1, _ := ctx.Locals("AccessLogged").(bool)
+	return b1
+}
+
+func WithAccessLogSettings(settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("accessLog")
+	}
+
+	accessLogSettings = NewAccessLogSettings(_settings)
+}
+
+func GetAccessLogSettings() *AccessLogSettings {
+	if accessLogSettings == nil {
+		return NewAccessLogSettings(map[string]interface{}{})
+	}
+
+	return accessLogSettings
+}
+
+// BuildAccessLogEntry collects the fields of a finished request,
+// keys: time, method, route, uri, protocol, status, bytes, latency, latencyMs, clientIp, userAgent, referer, requestId, subject
+func BuildAccessLogEntry(ctx *fiber.Ctx, start time.Time, err error, settings ...*AccessLogSettings) map[string]interface{} {
+	var st *AccessLogSettings
+
+	if len(settings) > 0 && settings[0] != nil {
+		st = settings[0]
+	} else {
+		st = GetAccessLogSettings()
+	}
+
+	statusCode := resolveStatusCode(ctx, err)
+	latency := time.Since(start)
+	resp := ctx.Response()
+	bytesSent := len(resp.Body())
+
+	if resp.IsBodyStream() && resp.Header.ContentLength() > 0 {
+		bytesSent = resp.Header.ContentLength()
+	}
+
+	return map[string]interface{}{
+		"time":      start,
+		"method":    ctx.Method(),
+		"route":     resolveRoutePath(ctx, statusCode),
+		"uri":       string(ctx.Request().RequestURI()),
+		"protocol":  string(ctx.Request().Header.Protocol()),
+		"status":    statusCode,
+		"bytes":     bytesSent,
+		"latency":   latency.String(),
+		"latencyMs": fmt.Sprintf("%.3f", float64(latency)/float64(time.Millisecond)),
+		"clientIp":  GetClientIp(ctx),
+		"userAgent": ctx.Get(fiber.HeaderUserAgent),
+		"referer":   ctx.Get(fiber.HeaderReferer),
+		"requestId": GetRequestId(ctx),
+		"subject":   accessLogSubject(ctx, st.SubjectClaim()),
+	}
+}
+
+func FormatAccessLog(entry map[string]interface{}, settings ...*AccessLogSettings) string {
+	var st *AccessLogSettings
+
+	if len(settings) > 0 && settings[0] != nil {
+		st = settings[0]
+	} else {
+		st = GetAccessLogSettings()
+	}
+
+	switch st.Format() {
+	case "json":
+		map1 := map[string]interface{}{}
+
+		for key, value := range entry {
+			if t1, ok := value.(time.Time); ok {
+				value = t1.Format(time.RFC3339)
+			}
+
+			map1[key] = value
+		}
+
+		return strings.TrimSpace(jsonx.ToJson(map1))
+	case "template":
+		return accessLogPlaceholder.ReplaceAllStringFunc(st.Template(), func(s1 string) string {
+			value, ok := entry[s1[1:len(s1)-1]]
+
+			if !ok {
+				return s1
+			}
+
+			if t1, ok := value.(time.Time); ok {
+				return t1.Format(time.RFC3339)
+			}
+
+			return castx.ToString(value)
+		})
+	default:
+		return formatCombinedAccessLog(entry)
+	}
+}
+
+func formatCombinedAccessLog(entry map[string]interface{}) string {
+	var logTime string
+
+	if t1, ok := entry["time"].(time.Time); ok {
+		logTime = t1.Format("02/Jan/2006:15:04:05 -0700")
+	}
+
+	bytesSent := "-"
+
+	if n1 := castx.ToInt(entry["bytes"]); n1 > 0 {
+		bytesSent = fmt.Sprintf("%d", n1)
+	}
+
+	return fmt.Sprintf(
+		`%s - %s [%s] "%s %s %s" %d %s "%s" "%s" %sms %s`,
+		accessLogField(entry["clientIp"]),
+		accessLogField(entry["subject"]),
+		logTime,
+		castx.ToString(entry["method"]),
+		castx.ToString(entry["uri"]),
+		castx.ToString(entry["protocol"]),
+		castx.ToInt(entry["status"]),
+		bytesSent,
+		accessLogField(entry["referer"]),
+		accessLogField(entry["userAgent"]),
+		castx.ToString(entry["latencyMs"]),
+		accessLogField(entry["requestId"]),
+	)
+}
+
+func accessLogField(value interface{}) string {
+	s1 := castx.ToString(value)
+
+	if s1 == "" {
+		return "-"
+	}
+
+	return strings.ReplaceAll(s1, `"`, `\"`)
+}
+
+func accessLogSubject(ctx *fiber.Ctx, claimName string) string {
+	if ctx.Get(fiber.HeaderAuthorization) == "" {
+		return ""
+	}
+
+	token := GetJwt(ctx)
+
+	if token == nil || !token.Valid {
+		return ""
+	}
+
+	return JwtClaim(token, claimName)
+}
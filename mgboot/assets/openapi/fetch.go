@@ -0,0 +1,75 @@
+//go:build ignore
+// +build ignore
+
+// fetch downloads the pinned swagger ui and redoc bundles into dist, they are embedded by mgboot and served
+// under the ui path, run it with go generate from the mgboot package after changing a version
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const swaggerUiVersion = "5.17.14"
+const redocVersion = "2.1.5"
+
+func main() {
+	bundles := map[string]string{
+		"swagger-ui-bundle.js": "https://unpkg.com/swagger-ui-dist@" + swaggerUiVersion + "/swagger-ui-bundle.js",
+		"swagger-ui.css":       "https://unpkg.com/swagger-ui-dist@" + swaggerUiVersion + "/swagger-ui.css",
+		"redoc.standalone.js":  "https://unpkg.com/redoc@" + redocVersion + "/bundles/redoc.standalone.js",
+	}
+
+	dir := filepath.Join("assets", "openapi", "dist")
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fail(err)
+	}
+
+	for fileName, url := range bundles {
+		if err := download(url, filepath.Join(dir, fileName)); err != nil {
+			fail(err)
+		}
+	}
+
+	versions := strings.Join([]string{
+		"swagger-ui-dist " + swaggerUiVersion,
+		"redoc " + redocVersion,
+		"",
+	}, "\n")
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "VERSIONS"), []byte(versions), 0644); err != nil {
+		fail(err)
+	}
+}
+
+func download(url, fpath string) error {
+	resp, err := http.Get(url)
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: status %d", url, resp.StatusCode)
+	}
+
+	buf, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(fpath, buf, 0644)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "fetch:", err)
+	os.Exit(1)
+}
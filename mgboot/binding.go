@@ -0,0 +1,703 @@
+package mgboot
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/meiguonet/mgboot-go-common/enum/DatetimeFormat"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"github.com/meiguonet/mgboot-go-common/util/mapx"
+	"github.com/meiguonet/mgboot-go-common/util/stringx"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+var fileHeaderType = reflect.TypeOf(&multipart.FileHeader{})
+
+var bindTimeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	DatetimeFormat.Full,
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+}
+
+type bindSources struct {
+	ctx   *fiber.Ctx
+	body  map[string]interface{}
+	form  map[string]interface{}
+	query map[string]interface{}
+	files map[string][]*multipart.FileHeader
+}
+
+func Bind(ctx *fiber.Ctx, dto interface{}) error {
+	rv := reflect.ValueOf(dto)
+
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("in mgboot.Bind function, dto must be a non-nil pointer to struct")
+	}
+
+	src := newBindSources(ctx)
+	bindErrors := map[string]string{}
+	bindStructFields(rv.Elem(), src, "", bindErrors)
+
+	if len(bindErrors) > 0 {
+		return NewValidateError(bindErrors)
+	}
+
+	return ValidateStruct(dto, resolveValidateLocale(ctx))
+}
+
+func newBindSources(ctx *fiber.Ctx) *bindSources {
+	src := &bindSources{
+		ctx:   ctx,
+		body:  map[string]interface{}{},
+		form:  map[string]interface{}{},
+		query: map[string]interface{}{},
+		files: map[string][]*multipart.FileHeader{},
+	}
+
+	src.query = GetQueryParamTree(ctx)
+
+	isPost := ctx.Request().Header.IsPost()
+	isPut := ctx.Request().Header.IsPut()
+	isPatch := ctx.Request().Header.IsPatch()
+	isDelete := ctx.Request().Header.IsDelete()
+	hasBody := isPost || isPut || isPatch || isDelete
+
+	if !hasBody {
+		return src
+	}
+
+	if ctx.Is("json") {
+		if len(ctx.Body()) > 0 {
+			src.body = jsonx.MapFrom(utils.CopyBytes(ctx.Body()))
+		}
+
+		return src
+	}
+
+	if ctx.Is("xml") {
+		if len(ctx.Body()) > 0 {
+			src.body = castx.ToStringMap(mapx.FromXml(utils.CopyBytes(ctx.Body())))
+		}
+
+		return src
+	}
+
+	contentType := strings.ToLower(ctx.Get(fiber.HeaderContentType))
+	src.form = GetFormDataTree(ctx)
+
+	if strings.Contains(contentType, fiber.MIMEMultipartForm) {
+		if form, err := ctx.MultipartForm(); err == nil && form != nil {
+			src.files = form.File
+		}
+	}
+
+	return src
+}
+
+func bindStructFields(rv reflect.Value, src *bindSources, prefix string, bindErrors map[string]string) {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		fv := rv.Field(i)
+
+		if !fv.CanSet() {
+			continue
+		}
+
+		if field.Anonymous && bindTagName(field) == "" {
+			ft := field.Type
+
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct && ft != timeType {
+				if fv.Kind() == reflect.Ptr {
+					if fv.IsNil() {
+						fv.Set(reflect.New(ft))
+					}
+
+					fv = fv.Elem()
+				}
+
+				bindStructFields(fv, src, prefix, bindErrors)
+				continue
+			}
+		}
+
+		if field.Tag.Get("json") == "-" || field.Tag.Get("bind") == "-" {
+			continue
+		}
+
+		raw, found, fieldName := lookupBindValue(src, field)
+
+		if !found {
+			continue
+		}
+
+		fieldPath := joinFieldPath(prefix, fieldName)
+
+		if err := assignBindValue(fv, raw, field, fieldPath, bindErrors); err != nil {
+			bindErrors[fieldPath] = err.Error()
+		}
+	}
+}
+
+func lookupBindValue(src *bindSources, field reflect.StructField) (interface{}, bool, string) {
+	if name := tagName(field, "path"); name != "" {
+		value := src.ctx.Params(name)
+
+		if value == "" {
+			return nil, false, name
+		}
+
+		s1, _ := url.PathUnescape(value)
+		return s1, true, name
+	}
+
+	if name := tagName(field, "header"); name != "" {
+		value := src.ctx.Get(name)
+		return value, value != "", name
+	}
+
+	if name := tagName(field, "form"); name != "" {
+		if isFileHeaderField(field.Type) {
+			files := src.files[name]
+			return files, len(files) > 0, name
+		}
+
+		value, ok := src.form[name]
+		return value, ok, name
+	}
+
+	if name := tagName(field, "query"); name != "" {
+		value, ok := src.query[name]
+		return value, ok, name
+	}
+
+	names := candidateFieldNames(field, "json", "xml")
+
+	for _, name := range names {
+		if isFileHeaderField(field.Type) {
+			if files := src.files[name]; len(files) > 0 {
+				return files, true, name
+			}
+
+			continue
+		}
+
+		if value, ok := src.body[name]; ok {
+			return value, true, name
+		}
+
+		if value, ok := src.form[name]; ok {
+			return value, true, name
+		}
+
+		if value, ok := src.query[name]; ok {
+			return value, true, name
+		}
+	}
+
+	return nil, false, names[0]
+}
+
+func assignBindValue(rv reflect.Value, raw interface{}, field reflect.StructField, fieldPath string, bindErrors map[string]string) error {
+	if raw == nil {
+		return nil
+	}
+
+	if rv.Kind() == reflect.Ptr && rv.Type() != fileHeaderType {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+
+		return assignBindValue(rv.Elem(), raw, field, fieldPath, bindErrors)
+	}
+
+	if rv.Type() == fileHeaderType {
+		if files, ok := raw.([]*multipart.FileHeader); ok && len(files) > 0 {
+			rv.Set(reflect.ValueOf(files[0]))
+		}
+
+		return nil
+	}
+
+	if rv.Type() == timeType {
+		t1, err := parseBindTime(raw, field.Tag.Get("layout"))
+
+		if err != nil {
+			return err
+		}
+
+		rv.Set(reflect.ValueOf(t1))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		map1, ok := raw.(map[string]interface{})
+
+		if !ok {
+			return errors.New("必须是对象")
+		}
+
+		bindStructFromMap(rv, map1, fieldPath, bindErrors)
+		return nil
+	case reflect.Slice:
+		if rv.Type().Elem() == fileHeaderType {
+			if files, ok := raw.([]*multipart.FileHeader); ok {
+				rv.Set(reflect.ValueOf(files))
+			}
+
+			return nil
+		}
+
+		items := toBindSlice(raw)
+		slice := reflect.MakeSlice(rv.Type(), len(items), len(items))
+
+		for idx, item := range items {
+			itemPath := fmt.Sprintf("%s[%d]", fieldPath, idx)
+
+			if err := assignBindValue(slice.Index(idx), item, field, itemPath, bindErrors); err != nil {
+				bindErrors[itemPath] = err.Error()
+			}
+		}
+
+		rv.Set(slice)
+		return nil
+	case reflect.Map:
+		map1, ok := raw.(map[string]interface{})
+
+		if !ok || rv.Type().Key().Kind() != reflect.String {
+			return errors.New("必须是对象")
+		}
+
+		dstMap := reflect.MakeMapWithSize(rv.Type(), len(map1))
+
+		for key, value := range map1 {
+			itemPath := joinFieldPath(fieldPath, key)
+			item := reflect.New(rv.Type().Elem()).Elem()
+
+			if err := assignBindValue(item, value, field, itemPath, bindErrors); err != nil {
+				bindErrors[itemPath] = err.Error()
+				continue
+			}
+
+			dstMap.SetMapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()), item)
+		}
+
+		rv.Set(dstMap)
+		return nil
+	case reflect.Interface:
+		// a non-empty interface only accepts the values implementing it
+		if !reflect.TypeOf(raw).AssignableTo(rv.Type()) {
+			return errors.New("数据类型不匹配")
+		}
+
+		rv.Set(reflect.ValueOf(raw))
+		return nil
+	}
+
+	if items, ok := raw.([]interface{}); ok {
+		if len(items) < 1 {
+			return nil
+		}
+
+		raw = items[0]
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		if _, ok := raw.(map[string]interface{}); ok {
+			return errors.New("必须是字符串")
+		}
+
+		value := bindScalarString(raw)
+
+		switch field.Tag.Get("sanitize") {
+		case "purify":
+			value = PurifyHtml(value)
+		case "striptags":
+			value = stringx.StripTags(value)
+		}
+
+		rv.SetString(value)
+	case reflect.Bool:
+		b1, err := castx.ToBoolE(raw)
+
+		if err != nil {
+			return errors.New("必须是布尔值")
+		}
+
+		rv.SetBool(b1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.Type() == reflect.TypeOf(time.Duration(0)) {
+			if s1, ok := raw.(string); ok {
+				d1, err := castx.ToDurationE(s1)
+
+				if err != nil {
+					return errors.New("必须是时间间隔")
+				}
+
+				rv.SetInt(int64(d1))
+				return nil
+			}
+		}
+
+		n1, err := strconv.ParseInt(bindScalarString(raw), 10, 64)
+
+		if err != nil || rv.OverflowInt(n1) {
+			return errors.New("必须是整数")
+		}
+
+		rv.SetInt(n1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n1, err := strconv.ParseUint(bindScalarString(raw), 10, 64)
+
+		if err != nil || rv.OverflowUint(n1) {
+			return errors.New("必须是非负整数")
+		}
+
+		rv.SetUint(n1)
+	case reflect.Float32, reflect.Float64:
+		n1, err := strconv.ParseFloat(bindScalarString(raw), 64)
+
+		if err != nil || rv.OverflowFloat(n1) {
+			return errors.New("必须是数字")
+		}
+
+		rv.SetFloat(n1)
+	default:
+		return fmt.Errorf("unsupported field type: %s", rv.Type().String())
+	}
+
+	return nil
+}
+
+func bindStructFromMap(rv reflect.Value, map1 map[string]interface{}, prefix string, bindErrors map[string]string) {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+
+		if !fv.CanSet() || field.Tag.Get("json") == "-" || field.Tag.Get("bind") == "-" {
+			continue
+		}
+
+		if field.Anonymous && bindTagName(field) == "" && field.Type.Kind() == reflect.Struct && field.Type != timeType {
+			bindStructFromMap(fv, map1, prefix, bindErrors)
+			continue
+		}
+
+		for _, name := range candidateFieldNames(field, "json", "xml", "form", "query") {
+			raw, ok := map1[name]
+
+			if !ok {
+				continue
+			}
+
+			fieldPath := joinFieldPath(prefix, name)
+
+			if err := assignBindValue(fv, raw, field, fieldPath, bindErrors); err != nil {
+				bindErrors[fieldPath] = err.Error()
+			}
+
+			break
+		}
+	}
+}
+
+func parseBindTime(raw interface{}, layout string) (time.Time, error) {
+	if items, ok := raw.([]interface{}); ok && len(items) > 0 {
+		raw = items[0]
+	}
+
+	switch t := raw.(type) {
+	case time.Time:
+		return t, nil
+	case float64:
+		return time.Unix(int64(t), 0), nil
+	case int64:
+		return time.Unix(t, 0), nil
+	case int:
+		return time.Unix(int64(t), 0), nil
+	}
+
+	s1 := strings.TrimSpace(castx.ToString(raw))
+
+	if s1 == "" {
+		return time.Time{}, nil
+	}
+
+	layouts := bindTimeLayouts
+
+	if layout != "" {
+		layouts = []string{layout}
+	} else if stringx.IsInt(s1) {
+		return time.Unix(castx.ToInt64(s1), 0), nil
+	}
+
+	for _, l1 := range layouts {
+		if t1, err := time.ParseInLocation(l1, s1, time.Local); err == nil {
+			return t1, nil
+		}
+	}
+
+	return time.Time{}, errors.New("不是有效的时间格式")
+}
+
+func bindScalarString(raw interface{}) string {
+	switch t := raw.(type) {
+	case string:
+		return strings.TrimSpace(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32)
+	}
+
+	return strings.TrimSpace(castx.ToString(raw))
+}
+
+func toBindSlice(raw interface{}) []interface{} {
+	switch t := raw.(type) {
+	case []interface{}:
+		return t
+	case []string:
+		items := make([]interface{}, 0, len(t))
+
+		for _, s1 := range t {
+			items = append(items, s1)
+		}
+
+		return items
+	case map[string]interface{}:
+		keys := make([]int, 0, len(t))
+
+		for key := range t {
+			n1, err := strconv.Atoi(key)
+
+			if err != nil {
+				return []interface{}{t}
+			}
+
+			keys = append(keys, n1)
+		}
+
+		sort.Ints(keys)
+		items := make([]interface{}, 0, len(keys))
+
+		for _, n1 := range keys {
+			items = append(items, t[strconv.Itoa(n1)])
+		}
+
+		return items
+	}
+
+	return []interface{}{raw}
+}
+
+func buildParamTree(values map[string][]string) map[string]interface{} {
+	tree := map[string]interface{}{}
+	keys := make([]string, 0, len(values))
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		parts := values[key]
+
+		if key == "" {
+			continue
+		}
+
+		segments := splitBracketKey(key)
+
+		if len(segments) == 1 {
+			if len(parts) == 1 {
+				tree[key] = parts[0]
+			} else {
+				items := make([]interface{}, 0, len(parts))
+
+				for _, p := range parts {
+					items = append(items, p)
+				}
+
+				tree[key] = items
+			}
+
+			continue
+		}
+
+		for _, p := range parts {
+			setParamTreeValue(tree, segments, p)
+		}
+	}
+
+	for key, value := range tree {
+		tree[key] = normalizeParamTreeNode(value)
+	}
+
+	return tree
+}
+
+func normalizeParamTreeNode(node interface{}) interface{} {
+	map1, ok := node.(map[string]interface{})
+
+	if !ok {
+		return node
+	}
+
+	for key, value := range map1 {
+		map1[key] = normalizeParamTreeNode(value)
+	}
+
+	for i := 0; i < len(map1); i++ {
+		if _, ok := map1[strconv.Itoa(i)]; !ok {
+			return map1
+		}
+	}
+
+	items := make([]interface{}, 0, len(map1))
+
+	for i := 0; i < len(map1); i++ {
+		items = append(items, map1[strconv.Itoa(i)])
+	}
+
+	return items
+}
+
+func splitBracketKey(key string) []string {
+	idx := strings.Index(key, "[")
+
+	if idx < 1 || !strings.HasSuffix(key, "]") {
+		return []string{key}
+	}
+
+	segments := []string{key[:idx]}
+	rest := key[idx:]
+
+	for rest != "" {
+		if !strings.HasPrefix(rest, "[") {
+			return []string{key}
+		}
+
+		end := strings.Index(rest, "]")
+
+		if end < 0 {
+			return []string{key}
+		}
+
+		segments = append(segments, rest[1:end])
+		rest = rest[end+1:]
+	}
+
+	return segments
+}
+
+func setParamTreeValue(node map[string]interface{}, segments []string, value string) {
+	key := segments[0]
+
+	if len(segments) == 1 {
+		node[key] = value
+		return
+	}
+
+	next := segments[1]
+
+	if next == "" && len(segments) == 2 {
+		items, _ := node[key].([]interface{})
+		node[key] = append(items, value)
+		return
+	}
+
+	child, ok := node[key].(map[string]interface{})
+
+	if !ok {
+		child = map[string]interface{}{}
+		node[key] = child
+	}
+
+	if next == "" {
+		next = strconv.Itoa(len(child))
+		segments = append([]string{key, next}, segments[2:]...)
+	}
+
+	setParamTreeValue(child, segments[1:], value)
+}
+
+func tagName(field reflect.StructField, tag string) string {
+	s1 := field.Tag.Get(tag)
+
+	if s1 == "" || s1 == "-" {
+		return ""
+	}
+
+	if strings.Contains(s1, ",") {
+		s1 = strings.TrimSpace(s1[:strings.Index(s1, ",")])
+	}
+
+	return s1
+}
+
+func bindTagName(field reflect.StructField) string {
+	for _, tag := range []string{"path", "query", "header", "form", "json", "xml"} {
+		if name := tagName(field, tag); name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func candidateFieldNames(field reflect.StructField, tags ...string) []string {
+	names := make([]string, 0)
+
+	for _, tag := range tags {
+		if name := tagName(field, tag); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) > 0 {
+		return names
+	}
+
+	return []string{field.Name, stringx.Lcfirst(field.Name)}
+}
+
+func isFileHeaderField(rt reflect.Type) bool {
+	return rt == fileHeaderType || (rt.Kind() == reflect.Slice && rt.Elem() == fileHeaderType)
+}
+
+func joinFieldPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "." + name
+}
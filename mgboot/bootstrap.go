@@ -0,0 +1,200 @@
+package mgboot
+
+import (
+	"context"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-dal/poolx"
+	"github.com/meiguonet/mgboot-go-fiber/cachex"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// boot order of the builtin components, logx.AppComponent and taskx.AppComponent use AppOrderLogging and AppOrderTasks
+const (
+	AppOrderConfig    = 0
+	AppOrderLogging   = 100
+	AppOrderSettings  = 200
+	AppOrderPools     = 300
+	AppOrderCache     = 400
+	AppOrderContainer = 500
+	AppOrderTasks     = 600
+)
+
+type AppHook func(ctx context.Context, app *Application) error
+
+func configComponent(fpath string) AppComponent {
+	return NewAppComponent("config", AppOrderConfig, func(_ *Application) error {
+		buf, err := ioutil.ReadFile(fpath)
+
+		if err != nil {
+			return err
+		}
+
+		if strings.ToLower(filepath.Ext(fpath)) == ".json" {
+			AppConf.InitFromJson(buf)
+		} else {
+			AppConf.InitFromYaml(buf)
+		}
+
+		return nil
+	})
+}
+
+// settingsComponent applies the settings whose section exists in AppConf
+func settingsComponent() AppComponent {
+	boot := func(_ *Application) error {
+		if len(ErrorHandlers()) < 1 {
+			WithBuiltinErrorHandlers()
+		}
+
+		sections := map[string]func(settings ...map[string]interface{}){
+			"accessLog":    WithAccessLogSettings,
+			"compress":     WithCompressSettings,
+			"health":       WithHealthSettings,
+			"cors":         WithCorsSettings,
+			"htmlPurifier": WithHtmlPurifierSettings,
+			"imageProcess": WithImageProcessSettings,
+			"logging.mask": WithMaskSettings,
+			"metrics":      WithMetricsSettings,
+			"openapi":      WithOpenApiSettings,
+			"storage":      WithStorageSettings,
+			"upload":       WithUploadSettings,
+		}
+
+		for key, fn := range sections {
+			if len(AppConf.GetMap(key)) > 0 {
+				fn()
+			}
+		}
+
+		if len(AppConf.GetMap("template")) > 0 {
+			if err := WithTemplateSettings(); err != nil {
+				return err
+			}
+		}
+
+		if proxies := AppConf.GetStringSlice("trustedProxies"); len(proxies) > 0 {
+			WithTrustedProxies(proxies...)
+		}
+
+		if len(AppConf.GetMap("ipFilter")) > 0 {
+			ReloadIpFilterRules()
+		}
+
+		for key := range AppConf.GetMap("jwt") {
+			WithJwtSettings(key)
+		}
+
+		for name := range AppConf.GetMap("routeProfiles") {
+			WithRouteProfile(name)
+		}
+
+		return WithTracingSettings()
+	}
+
+	shutdown := func(ctx context.Context) error {
+		var timeout time.Duration
+
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+
+		return ShutdownTracing(timeout)
+	}
+
+	return NewAppComponent("settings", AppOrderSettings, boot, shutdown)
+}
+
+// poolsComponent opens the redis pool and the db pool when their section exists in AppConf,
+// the redis health check is registered with the redis pool
+func poolsComponent() AppComponent {
+	var redisPoolInited, dbPoolInited bool
+
+	boot := func(_ *Application) error {
+		if len(AppConf.GetMap("redis")) > 0 {
+			poolx.InitRedisPool()
+			redisPoolInited = poolx.GetRedisPool() != nil
+			WithHealthCheck("redis", RedisPoolHealthCheck())
+		}
+
+		if len(AppConf.GetMap("datasource")) > 0 {
+			poolx.InitDbPool()
+			dbPoolInited = poolx.GetDbPool() != nil
+		}
+
+		return nil
+	}
+
+	shutdown := func(_ context.Context) error {
+		if redisPoolInited {
+			poolx.CloseRedisPool()
+		}
+
+		if dbPoolInited {
+			poolx.CloseDbPool()
+		}
+
+		return nil
+	}
+
+	return NewAppComponent("pools", AppOrderPools, boot, shutdown)
+}
+
+// cacheComponent supported keys of the cache section: dir, keyPrefix, defaultStore, stores (memory|redis|file),
+// memoryDefaultTtl, memoryCleanupInterval, the memory store is always available,
+// the cacheDir health check is registered with the file store
+func cacheComponent() AppComponent {
+	return NewAppComponent("cache", AppOrderCache, func(_ *Application) error {
+		settings := AppConf.GetMap("cache")
+
+		if dir := castx.ToString(settings["dir"]); dir != "" {
+			cachex.CacheDir(dir)
+		}
+
+		if prefix := castx.ToString(settings["keyPrefix"]); prefix != "" {
+			cachex.CacheKeyPrefix(prefix)
+		}
+
+		cleanupInterval := 10 * time.Minute
+
+		if d1 := castx.ToDuration(settings["memoryCleanupInterval"]); d1 > 0 {
+			cleanupInterval = d1
+		}
+
+		cachex.WithMemoryCache(castx.ToDuration(settings["memoryDefaultTtl"]), cleanupInterval)
+
+		for _, store := range castx.ToStringSlice(settings["stores"]) {
+			switch strings.ToLower(store) {
+			case "redis":
+				cachex.WithRedisCache()
+			case "file":
+				cachex.WithFileCache()
+
+				WithHealthCheck("cacheDir", DirWritableHealthCheck(func() string {
+					return cachex.CacheDir()
+				}))
+			}
+		}
+
+		if store := castx.ToString(settings["defaultStore"]); store != "" {
+			cachex.DefaultStore(store)
+		}
+
+		return nil
+	})
+}
+
+func containerComponent() AppComponent {
+	boot := func(_ *Application) error {
+		return DefaultContainer().Start(context.Background())
+	}
+
+	shutdown := func(ctx context.Context) error {
+		return DefaultContainer().Stop(ctx)
+	}
+
+	return NewAppComponent("container", AppOrderContainer, boot, shutdown)
+}
@@ -0,0 +1,200 @@
+package mgboot
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"net"
+	"strings"
+	"sync"
+)
+
+var trustedProxies []*net.IPNet
+var trustedProxiesMu = &sync.RWMutex{}
+
+// only a proxy on the same host is trusted by default, the private ranges must be listed explicitly
+// since a client inside them could otherwise forge X-Forwarded-For
+var defaultTrustedProxies = []string{
+	"127.0.0.0/8",
+	"::1/128",
+}
+
+func WithTrustedProxies(proxies ...string) {
+	if len(proxies) < 1 {
+		proxies = AppConf.GetStringSlice("trustedProxies")
+	}
+
+	// nothing configured keeps the loopback default
+	var nets []*net.IPNet
+
+	for _, s1 := range proxies {
+		if ipNet := ParseIpNet(s1); ipNet != nil {
+			nets = append(nets, ipNet)
+		}
+	}
+
+	trustedProxiesMu.Lock()
+	trustedProxies = nets
+	trustedProxiesMu.Unlock()
+}
+
+func TrustedProxies() []*net.IPNet {
+	trustedProxiesMu.RLock()
+	nets := trustedProxies
+	trustedProxiesMu.RUnlock()
+
+	if nets != nil {
+		return nets
+	}
+
+	nets = make([]*net.IPNet, 0, len(defaultTrustedProxies))
+
+	for _, s1 := range defaultTrustedProxies {
+		nets = append(nets, ParseIpNet(s1))
+	}
+
+	return nets
+}
+
+func IsTrustedProxy(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, ipNet := range TrustedProxies() {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ParseIpNet(s1 string) *net.IPNet {
+	s1 = strings.TrimSpace(s1)
+
+	if s1 == "" {
+		return nil
+	}
+
+	if strings.Contains(s1, "/") {
+		_, ipNet, err := net.ParseCIDR(s1)
+
+		if err != nil {
+			return nil
+		}
+
+		return ipNet
+	}
+
+	ip := net.ParseIP(s1)
+
+	if ip == nil {
+		return nil
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+func resolveClientIp(ctx *fiber.Ctx) string {
+	if s1, ok := ctx.Locals("ClientIp").(string); ok && s1 != "" {
+		return s1
+	}
+
+	remoteIp := normalizeIp(ctx.Context().RemoteIP())
+	clientIp := remoteIp
+
+	if IsTrustedProxy(remoteIp) {
+		chain := forwardedForChain(ctx)
+
+		for i := len(chain) - 1; i >= 0; i-- {
+			ip := chain[i]
+
+			if ip == nil {
+				break
+			}
+
+			clientIp = ip
+
+			if !IsTrustedProxy(ip) {
+				break
+			}
+		}
+	}
+
+	var ip string
+
+	if clientIp != nil {
+		ip = clientIp.String()
+	}
+
+	ctx.Locals("ClientIp", ip)
+	return ip
+}
+
+func forwardedForChain(ctx *fiber.Ctx) []net.IP {
+	chain := make([]net.IP, 0)
+
+	if s1 := ctx.Get("Forwarded"); s1 != "" {
+		for _, element := range strings.Split(s1, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				pair = strings.TrimSpace(pair)
+
+				if len(pair) < 4 || !strings.EqualFold(pair[:4], "for=") {
+					continue
+				}
+
+				chain = append(chain, parseForwardedNode(pair[4:]))
+			}
+		}
+
+		return chain
+	}
+
+	if s1 := ctx.Get(fiber.HeaderXForwardedFor); s1 != "" {
+		for _, part := range strings.Split(s1, ",") {
+			chain = append(chain, parseForwardedNode(part))
+		}
+
+		return chain
+	}
+
+	if s1 := ctx.Get("X-Real-IP"); s1 != "" {
+		chain = append(chain, parseForwardedNode(s1))
+	}
+
+	return chain
+}
+
+func parseForwardedNode(node string) net.IP {
+	node = strings.Trim(strings.TrimSpace(node), `"`)
+
+	if node == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(node, "[") {
+		if idx := strings.Index(node, "]"); idx > 0 {
+			node = node[1:idx]
+		}
+	} else if strings.Count(node, ":") == 1 {
+		node = node[:strings.Index(node, ":")]
+	}
+
+	return normalizeIp(net.ParseIP(node))
+}
+
+func normalizeIp(ip net.IP) net.IP {
+	if ip == nil {
+		return nil
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4
+	}
+
+	return ip
+}
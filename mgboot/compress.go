@@ -0,0 +1,159 @@
+package mgboot
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/valyala/fasthttp"
+	"hash/crc32"
+	"strings"
+)
+
+var compressSettings *CompressSettings
+
+func WithCompressSettings(settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("compress")
+	}
+
+	compressSettings = NewCompressSettings(_settings)
+}
+
+func GetCompressSettings() *CompressSettings {
+	if compressSettings == nil {
+		return NewCompressSettings(map[string]interface{}{})
+	}
+
+	return compressSettings
+}
+
+func BuildEtag(buf []byte, weak ...bool) string {
+	if len(weak) > 0 && weak[0] {
+		return fmt.Sprintf(`W/"%x-%08x"`, len(buf), crc32.ChecksumIEEE(buf))
+	}
+
+	sum := sha1.Sum(buf)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func etagMatch(ifNoneMatch, etag string) bool {
+	ifNoneMatch = strings.TrimSpace(ifNoneMatch)
+
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+
+	if ifNoneMatch == "*" {
+		return true
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+
+	for _, s1 := range strings.Split(ifNoneMatch, ",") {
+		s1 = strings.TrimPrefix(strings.TrimSpace(s1), "W/")
+
+		if s1 == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func negotiateEncoding(acceptEncoding string, encodings []string) string {
+	acceptEncoding = strings.ToLower(strings.TrimSpace(acceptEncoding))
+
+	if acceptEncoding == "" || len(encodings) < 1 {
+		return ""
+	}
+
+	accepted := map[string]float64{}
+
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		part = strings.TrimSpace(part)
+
+		if part == "" {
+			continue
+		}
+
+		name := part
+		q := 1.0
+
+		if idx := strings.Index(part, ";"); idx >= 0 {
+			name = strings.TrimSpace(part[:idx])
+
+			for _, param := range strings.Split(part[idx+1:], ";") {
+				param = strings.TrimSpace(param)
+
+				if strings.HasPrefix(param, "q=") {
+					q = castx.ToFloat64(strings.TrimPrefix(param, "q="), 1.0)
+				}
+			}
+		}
+
+		accepted[name] = q
+	}
+
+	var selected string
+	var maxQ float64
+
+	for _, encoding := range encodings {
+		q, ok := accepted[encoding]
+
+		if !ok {
+			q, ok = accepted["*"]
+		}
+
+		if !ok || q <= maxQ {
+			continue
+		}
+
+		selected = encoding
+		maxQ = q
+	}
+
+	return selected
+}
+
+func compressBytes(encoding string, buf []byte, level ...int) ([]byte, error) {
+	lv := 6
+
+	if len(level) > 0 && level[0] > 0 {
+		lv = level[0]
+	}
+
+	switch encoding {
+	case "br":
+		if lv > 11 {
+			lv = 11
+		}
+
+		return fasthttp.AppendBrotliBytesLevel(nil, buf, lv), nil
+	case "gzip":
+		if lv > 9 {
+			lv = 9
+		}
+
+		return fasthttp.AppendGzipBytesLevel(nil, buf, lv), nil
+	case "zstd":
+		encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(lv)))
+
+		if err != nil {
+			return nil, err
+		}
+
+		defer encoder.Close()
+		return encoder.EncodeAll(buf, make([]byte, 0, len(buf))), nil
+	}
+
+	return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+}
@@ -0,0 +1,69 @@
+package mgboot
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"strings"
+)
+
+// ApplyCors adds the cors response headers, preflight is true when the request is a preflight
+// that has been answered and the handler chain should stop
+func ApplyCors(ctx *fiber.Ctx, settings ...*CorsSettings) (preflight bool) {
+	var st *CorsSettings
+
+	if len(settings) > 0 && settings[0] != nil {
+		st = settings[0]
+	} else if GetCorsSettings() != nil {
+		st = GetCorsSettings()
+	} else {
+		st = NewCorsSettings(map[string]interface{}{})
+	}
+
+	origin := ctx.Get(fiber.HeaderOrigin)
+	preflight = ctx.Method() == fiber.MethodOptions && ctx.Get(fiber.HeaderAccessControlRequestMethod) != ""
+
+	if origin == "" {
+		return false
+	}
+
+	ctx.Vary(fiber.HeaderOrigin)
+
+	if !st.IsOriginAllowed(origin) {
+		if preflight {
+			ctx.Status(fiber.StatusForbidden).Send([]byte{})
+		}
+
+		return preflight
+	}
+
+	if st.AllowCredentials() {
+		ctx.Set(fiber.HeaderAccessControlAllowOrigin, origin)
+		ctx.Set(fiber.HeaderAccessControlAllowCredentials, "true")
+	} else if st.IsOriginAllowed("*") {
+		ctx.Set(fiber.HeaderAccessControlAllowOrigin, "*")
+	} else {
+		ctx.Set(fiber.HeaderAccessControlAllowOrigin, origin)
+	}
+
+	if !preflight {
+		if len(st.ExposedHeaders()) > 0 {
+			ctx.Set(fiber.HeaderAccessControlExposeHeaders, strings.Join(st.ExposedHeaders(), ", "))
+		}
+
+		return false
+	}
+
+	ctx.Vary(fiber.HeaderAccessControlRequestMethod, fiber.HeaderAccessControlRequestHeaders)
+	ctx.Set(fiber.HeaderAccessControlAllowMethods, strings.Join(st.AllowedMethods(), ", "))
+
+	if len(st.AllowedHeaders()) > 0 {
+		ctx.Set(fiber.HeaderAccessControlAllowHeaders, strings.Join(st.AllowedHeaders(), ", "))
+	}
+
+	if st.MaxAge() > 0 {
+		ctx.Set(fiber.HeaderAccessControlMaxAge, fmt.Sprintf("%d", int64(st.MaxAge().Seconds())))
+	}
+
+	ctx.Status(fiber.StatusNoContent).Send([]byte{})
+	return true
+}
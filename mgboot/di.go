@@ -0,0 +1,106 @@
+package mgboot
+
+import (
+	"context"
+	ccachex "github.com/meiguonet/mgboot-go-common/cachex"
+	"github.com/meiguonet/mgboot-go-common/logx"
+	"github.com/meiguonet/mgboot-go-fiber/cachex"
+	"github.com/meiguonet/mgboot-go-fiber/enum/ServiceScope"
+	"sync"
+)
+
+var defaultContainer *Container
+var defaultContainerMu = &sync.Mutex{}
+
+// Starter is called by Container.Start once the service and its dependencies are created
+type Starter interface {
+	OnStart(ctx context.Context) error
+}
+
+// Stopper is called by Container.Stop for singletons and at the end of the request for request scoped services
+type Stopper interface {
+	OnStop(ctx context.Context) error
+}
+
+// DefaultContainer returns the container shared by the application, the builtin components are registered in it:
+// logx.Logger (the runtime logger, and named runtime, requestLog, executeTimeLog, accessLog),
+// cachex.ICache (the default store, and named memory, redis, file), *JwtSettings named by settings key
+// and []ErrorHandler, all of them read the current settings on every resolve
+func DefaultContainer() *Container {
+	defaultContainerMu.Lock()
+	defer defaultContainerMu.Unlock()
+
+	if defaultContainer == nil {
+		defaultContainer = NewContainer()
+		registerBuiltinComponents(defaultContainer)
+	}
+
+	return defaultContainer
+}
+
+func registerBuiltinComponents(c *Container) {
+	c.Provide(func() logx.Logger {
+		return RuntimeLogger()
+	}, ServiceScope.Transient)
+
+	c.ProvideNamed("runtime", func() logx.Logger {
+		return RuntimeLogger()
+	}, ServiceScope.Transient)
+
+	c.ProvideNamed("requestLog", func() logx.Logger {
+		return RequestLogLogger()
+	}, ServiceScope.Transient)
+
+	c.ProvideNamed("executeTimeLog", func() logx.Logger {
+		return ExecuteTimeLogLogger()
+	}, ServiceScope.Transient)
+
+	c.ProvideNamed("accessLog", func() logx.Logger {
+		return AccessLogLogger()
+	}, ServiceScope.Transient)
+
+	c.Provide(func() ccachex.ICache {
+		return cachex.Store(cachex.DefaultStore())
+	}, ServiceScope.Transient)
+
+	for _, name := range []string{"memory", "redis", "file"} {
+		storeName := name
+
+		c.ProvideNamed(storeName, func() ccachex.ICache {
+			return cachex.Store(storeName)
+		}, ServiceScope.Transient)
+	}
+
+	c.Provide(func() []ErrorHandler {
+		return ErrorHandlers()
+	}, ServiceScope.Transient)
+
+	for key := range jwtSettings {
+		registerJwtSettingsComponent(c, key)
+	}
+}
+
+func registerJwtSettingsComponent(c *Container, key string) {
+	c.ProvideNamed(key, func() *JwtSettings {
+		return GetJwtSettings(key)
+	}, ServiceScope.Transient)
+}
+
+// registerBuiltinJwtSettings keeps the default container in step with settings added after it was created
+func registerBuiltinJwtSettings(key string) {
+	defaultContainerMu.Lock()
+	c := defaultContainer
+	defaultContainerMu.Unlock()
+
+	if c != nil {
+		registerJwtSettingsComponent(c, key)
+	}
+}
+
+func firstServiceName(name []string) string {
+	if len(name) > 0 {
+		return name[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,223 @@
+package mgboot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"github.com/meiguonet/mgboot-go-dal/poolx"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+type HealthCheckFunc func(ctx context.Context) (details map[string]interface{}, err error)
+
+var healthSettings *HealthSettings
+var healthChecks = make([]*HealthCheck, 0)
+var healthChecksMu = &sync.RWMutex{}
+
+func WithHealthSettings(settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("health")
+	}
+
+	healthSettings = NewHealthSettings(_settings)
+}
+
+func GetHealthSettings() *HealthSettings {
+	if healthSettings == nil {
+		return NewHealthSettings(map[string]interface{}{})
+	}
+
+	return healthSettings
+}
+
+// WithHealthCheck registers a check, the one with the same name is replaced, see NewHealthCheck for the settings
+func WithHealthCheck(name string, check HealthCheckFunc, settings ...map[string]interface{}) {
+	hc := NewHealthCheck(name, check, settings...)
+	healthChecksMu.Lock()
+	defer healthChecksMu.Unlock()
+
+	for i, h1 := range healthChecks {
+		if h1.Name() == name {
+			healthChecks[i] = hc
+			return
+		}
+	}
+
+	healthChecks = append(healthChecks, hc)
+}
+
+func RemoveHealthCheck(name string) {
+	healthChecksMu.Lock()
+	defer healthChecksMu.Unlock()
+	checks := make([]*HealthCheck, 0, len(healthChecks))
+
+	for _, hc := range healthChecks {
+		if hc.Name() != name {
+			checks = append(checks, hc)
+		}
+	}
+
+	healthChecks = checks
+}
+
+func HealthChecks() []*HealthCheck {
+	healthChecksMu.RLock()
+	defer healthChecksMu.RUnlock()
+	checks := make([]*HealthCheck, len(healthChecks))
+	copy(checks, healthChecks)
+	return checks
+}
+
+// RunHealthChecks runs the liveness checks when liveness is set, otherwise all of the checks, concurrently
+func RunHealthChecks(ctx context.Context, liveness bool) (up bool, results []*HealthCheckResult) {
+	checks := make([]*HealthCheck, 0)
+
+	for _, hc := range HealthChecks() {
+		if liveness && !hc.Liveness() {
+			continue
+		}
+
+		checks = append(checks, hc)
+	}
+
+	results = make([]*HealthCheckResult, len(checks))
+	wg := &sync.WaitGroup{}
+	wg.Add(len(checks))
+
+	for i, hc := range checks {
+		go func(i int, hc *HealthCheck) {
+			defer wg.Done()
+			results[i] = hc.Run(ctx)
+		}(i, hc)
+	}
+
+	wg.Wait()
+	up = true
+
+	for _, r := range results {
+		if !r.Up() {
+			up = false
+			break
+		}
+	}
+
+	return
+}
+
+func HealthLiveHandler(settings ...*HealthSettings) fiber.Handler {
+	return healthHandler(true, settings...)
+}
+
+func HealthReadyHandler(settings ...*HealthSettings) fiber.Handler {
+	return healthHandler(false, settings...)
+}
+
+func healthHandler(liveness bool, settings ...*HealthSettings) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		var st *HealthSettings
+
+		if len(settings) > 0 && settings[0] != nil {
+			st = settings[0]
+		} else {
+			st = GetHealthSettings()
+		}
+
+		up, results := RunHealthChecks(ctx.Context(), liveness)
+		payload := map[string]interface{}{"status": "UP"}
+		statusCode := fiber.StatusOK
+
+		if !up {
+			payload["status"] = "DOWN"
+			statusCode = fiber.StatusServiceUnavailable
+		}
+
+		if st.ShowDetails() {
+			checks := map[string]interface{}{}
+
+			for _, r := range results {
+				checks[r.Name()] = r.ToMap()
+			}
+
+			payload["checks"] = checks
+		}
+
+		ctx.Set(fiber.HeaderCacheControl, "no-store")
+		ctx.Type("json", "utf8")
+		return ctx.Status(statusCode).SendString(jsonx.ToJson(payload))
+	}
+}
+
+// RedisPoolHealthCheck pings redis with a connection of the poolx redis pool
+func RedisPoolHealthCheck() HealthCheckFunc {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		pool := poolx.GetRedisPool()
+
+		if pool == nil {
+			return nil, errors.New("redis pool not initialized")
+		}
+
+		stats := pool.Stats()
+
+		details := map[string]interface{}{
+			"activeCount": stats.ActiveCount,
+			"idleCount":   stats.IdleCount,
+		}
+
+		conn, err := pool.GetContext(ctx)
+
+		if err != nil {
+			return details, err
+		}
+
+		defer conn.Close()
+
+		if _, err = conn.Do("PING"); err != nil {
+			return details, err
+		}
+
+		return details, nil
+	}
+}
+
+// DirWritableHealthCheck creates and removes a temp file in the dir returned by dir on every run,
+// the dir is created if not exists
+func DirWritableHealthCheck(dir func() string) HealthCheckFunc {
+	return func(_ context.Context) (map[string]interface{}, error) {
+		_dir := dir()
+
+		if _dir == "" {
+			return nil, errors.New("dir not available")
+		}
+
+		details := map[string]interface{}{"dir": _dir}
+
+		if stat, err := os.Stat(_dir); err != nil || !stat.IsDir() {
+			os.MkdirAll(_dir, 0755)
+		}
+
+		if stat, err := os.Stat(_dir); err != nil || !stat.IsDir() {
+			return details, fmt.Errorf("dir not exists: %s", _dir)
+		}
+
+		f, err := ioutil.TempFile(_dir, ".health-")
+
+		if err != nil {
+			return details, err
+		}
+
+		fpath := f.Name()
+		f.Close()
+		return details, os.Remove(fpath)
+	}
+}
@@ -0,0 +1,100 @@
+package mgboot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+)
+
+func DefaultImageInfoFunc() ImageInfoGetFunc {
+	return func(fh *multipart.FileHeader) map[string]interface{} {
+		if fh == nil {
+			return map[string]interface{}{}
+		}
+
+		f, err := fh.Open()
+
+		if err != nil {
+			return map[string]interface{}{}
+		}
+
+		defer f.Close()
+		return GetImageInfo(f)
+	}
+}
+
+func GetImageInfo(r io.Reader) map[string]interface{} {
+	head := make([]byte, 32)
+	n, _ := io.ReadFull(r, head)
+	head = head[:n]
+
+	if width, height, ok := webpImageSize(head); ok {
+		return map[string]interface{}{
+			"width":    width,
+			"height":   height,
+			"mimeType": "image/webp",
+		}
+	}
+
+	cfg, format, err := image.DecodeConfig(io.MultiReader(bytes.NewReader(head), r))
+
+	if err != nil {
+		return map[string]interface{}{}
+	}
+
+	return map[string]interface{}{
+		"width":    cfg.Width,
+		"height":   cfg.Height,
+		"mimeType": "image/" + format,
+	}
+}
+
+func GetImageInfoFromBuffer(buf []byte) map[string]interface{} {
+	return GetImageInfo(bytes.NewReader(buf))
+}
+
+func GetImageInfoFromFile(fpath string) map[string]interface{} {
+	buf, err := ioutil.ReadFile(fpath)
+
+	if err != nil {
+		return map[string]interface{}{}
+	}
+
+	return GetImageInfoFromBuffer(buf)
+}
+
+func webpImageSize(buf []byte) (int, int, bool) {
+	if len(buf) < 30 || string(buf[0:4]) != "RIFF" || string(buf[8:12]) != "WEBP" {
+		return 0, 0, false
+	}
+
+	switch string(buf[12:16]) {
+	case "VP8 ":
+		if buf[23] != 0x9d || buf[24] != 0x01 || buf[25] != 0x2a {
+			return 0, 0, false
+		}
+
+		width := int(binary.LittleEndian.Uint16(buf[26:28]) & 0x3fff)
+		height := int(binary.LittleEndian.Uint16(buf[28:30]) & 0x3fff)
+		return width, height, width > 0 && height > 0
+	case "VP8L":
+		if buf[20] != 0x2f {
+			return 0, 0, false
+		}
+
+		bits := binary.LittleEndian.Uint32(buf[21:25])
+		return int(bits&0x3fff) + 1, int((bits>>14)&0x3fff) + 1, true
+	case "VP8X":
+		width := int(uint32(buf[24])|uint32(buf[25])<<8|uint32(buf[26])<<16) + 1
+		height := int(uint32(buf[27])|uint32(buf[28])<<8|uint32(buf[29])<<16) + 1
+		return width, height, true
+	}
+
+	return 0, 0, false
+}
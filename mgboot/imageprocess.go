@@ -0,0 +1,362 @@
+package mgboot
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-fiber/cachex"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"strings"
+)
+
+var imageProcessSettings *ImageProcessSettings
+
+func WithImageProcessSettings(settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("imageProcess")
+	}
+
+	imageProcessSettings = NewImageProcessSettings(_settings)
+}
+
+func GetImageProcessSettings() *ImageProcessSettings {
+	if imageProcessSettings == nil {
+		return NewImageProcessSettings(map[string]interface{}{})
+	}
+
+	return imageProcessSettings
+}
+
+// ProcessImage applies orientation correction, cropping, resizing and format conversion,
+// re-encoding drops all metadata (EXIF etc.) of the source image.
+func ProcessImage(buf []byte, opts *ImageProcessOptions) ([]byte, string, error) {
+	settings := GetImageProcessSettings()
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(buf))
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if cfg.Width*cfg.Height > settings.MaxPixels() {
+		return nil, "", errors.New("image too large to process")
+	}
+
+	orientation := 1
+
+	if opts.orient && format == "jpeg" {
+		orientation = jpegExifOrientation(buf)
+	}
+
+	if opts.IsEmpty() && orientation == 1 {
+		return buf, "image/" + format, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(buf))
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	img = applyOrientation(img, orientation)
+
+	if !opts.crop.Empty() {
+		rect := opts.crop.Add(img.Bounds().Min).Intersect(img.Bounds())
+
+		if rect.Empty() {
+			return nil, "", errors.New("invalid image crop region")
+		}
+
+		img = cropImage(img, rect)
+	}
+
+	img = resizeImage(img, opts, settings)
+	outFormat := opts.format
+
+	if outFormat == "" {
+		outFormat = format
+	}
+
+	switch outFormat {
+	case "jpeg", "png", "gif", "webp":
+	default:
+		outFormat = "png"
+	}
+
+	quality := opts.quality
+
+	if quality < 1 {
+		quality = settings.Quality()
+	}
+
+	out := &bytes.Buffer{}
+
+	switch outFormat {
+	case "jpeg":
+		err = jpeg.Encode(out, flattenImage(img), &jpeg.Options{Quality: quality})
+	case "gif":
+		err = gif.Encode(out, img, nil)
+	case "webp":
+		var data []byte
+
+		if data, err = EncodeWebp(img); err == nil {
+			out.Write(data)
+		}
+	default:
+		err = png.Encode(out, img)
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return out.Bytes(), "image/" + outFormat, nil
+}
+
+func ProcessImageCached(buf []byte, opts *ImageProcessOptions) ([]byte, string, error) {
+	settings := GetImageProcessSettings()
+
+	if settings.CacheStore() == "" || opts.IsEmpty() {
+		return ProcessImage(buf, opts)
+	}
+
+	sum := sha1.Sum(buf)
+	cacheKey := "imageProcess." + hex.EncodeToString(sum[:]) + "." + opts.CacheKey()
+	store := cachex.Store(settings.CacheStore())
+
+	if s1, ok := store.Get(cacheKey).(string); ok && s1 != "" {
+		parts := strings.SplitN(s1, "|", 2)
+
+		if len(parts) == 2 {
+			if data, err := base64.StdEncoding.DecodeString(parts[1]); err == nil {
+				return data, parts[0], nil
+			}
+		}
+	}
+
+	data, mimeType, err := ProcessImage(buf, opts)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	store.Set(cacheKey, mimeType+"|"+base64.StdEncoding.EncodeToString(data), settings.CacheTtl())
+	return data, mimeType, nil
+}
+
+func resizeImage(img image.Image, opts *ImageProcessOptions, settings *ImageProcessSettings) image.Image {
+	width, height := opts.width, opts.height
+
+	if width > settings.MaxWidth() {
+		width = settings.MaxWidth()
+	}
+
+	if height > settings.MaxHeight() {
+		height = settings.MaxHeight()
+	}
+
+	rect := img.Bounds()
+	sw, sh := rect.Dx(), rect.Dy()
+
+	if (width < 1 && height < 1) || sw < 1 || sh < 1 {
+		return img
+	}
+
+	srcRect := rect
+
+	switch {
+	case width < 1:
+		width = maxInt(int(math.Round(float64(sw)*float64(height)/float64(sh))), 1)
+	case height < 1:
+		height = maxInt(int(math.Round(float64(sh)*float64(width)/float64(sw))), 1)
+	case opts.fit == "contain" || opts.fit == "inside":
+		scale := math.Min(float64(width)/float64(sw), float64(height)/float64(sh))
+
+		if opts.fit == "inside" && scale > 1 {
+			scale = 1
+		}
+
+		width = maxInt(int(math.Round(float64(sw)*scale)), 1)
+		height = maxInt(int(math.Round(float64(sh)*scale)), 1)
+	case opts.fit == "cover":
+		scale := math.Max(float64(width)/float64(sw), float64(height)/float64(sh))
+		cw := minInt(int(math.Round(float64(width)/scale)), sw)
+		ch := minInt(int(math.Round(float64(height)/scale)), sh)
+		x := rect.Min.X + (sw-cw)/2
+		y := rect.Min.Y + (sh-ch)/2
+		srcRect = image.Rect(x, y, x+cw, y+ch)
+	}
+
+	if width == sw && height == sh && srcRect == rect {
+		return img
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Rect, img, srcRect, xdraw.Src, nil)
+	return dst
+}
+
+func cropImage(img image.Image, rect image.Rectangle) image.Image {
+	dst := image.NewNRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(dst, dst.Rect, img, rect.Min, draw.Src)
+	return dst
+}
+
+func flattenImage(img image.Image) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(dst, dst.Rect, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Rect, img, img.Bounds().Min, draw.Over)
+	return dst
+}
+
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	rect := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(src, src.Rect, img, rect.Min, draw.Src)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+
+			si := src.PixOffset(sx, sy)
+			di := dst.PixOffset(x, y)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+
+	return dst
+}
+
+func jpegExifOrientation(buf []byte) int {
+	if len(buf) < 4 || buf[0] != 0xFF || buf[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(buf); {
+		if buf[i] != 0xFF {
+			return 1
+		}
+
+		marker := buf[i+1]
+
+		if marker == 0xFF {
+			i++
+			continue
+		}
+
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+
+		segLen := int(binary.BigEndian.Uint16(buf[i+2 : i+4]))
+
+		if segLen < 2 || i+2+segLen > len(buf) {
+			return 1
+		}
+
+		if marker == 0xE1 && segLen >= 8 && string(buf[i+4:i+10]) == "Exif\x00\x00" {
+			return tiffOrientation(buf[i+10 : i+2+segLen])
+		}
+
+		i += 2 + segLen
+	}
+
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var bo binary.ByteOrder
+
+	switch string(tiff[0:2]) {
+	case "II":
+		bo = binary.LittleEndian
+	case "MM":
+		bo = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(bo.Uint32(tiff[4:8]))
+
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+
+	count := int(bo.Uint16(tiff[offset : offset+2]))
+
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+
+		if entry+12 > len(tiff) {
+			break
+		}
+
+		if bo.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			if n := int(bo.Uint16(tiff[entry+8 : entry+10])); n >= 1 && n <= 8 {
+				return n
+			}
+
+			break
+		}
+	}
+
+	return 1
+}
+
+func minInt(n1, n2 int) int {
+	if n1 < n2 {
+		return n1
+	}
+
+	return n2
+}
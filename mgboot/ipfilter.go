@@ -0,0 +1,106 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"net"
+	"strings"
+	"sync"
+)
+
+type CountryLookup interface {
+	LookupCountry(ip net.IP) (string, error)
+}
+
+var ipFilterRules = map[string]*IpFilterRules{}
+var ipFilterMu = &sync.RWMutex{}
+var countryLookup CountryLookup
+
+func WithIpFilterRules(name string, settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("ipFilter." + name)
+	}
+
+	ipFilterMu.Lock()
+	ipFilterRules[name] = NewIpFilterRules(_settings)
+	ipFilterMu.Unlock()
+}
+
+func ReloadIpFilterRules(settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("ipFilter")
+	}
+
+	rules := map[string]*IpFilterRules{}
+
+	for name, value := range _settings {
+		rules[name] = NewIpFilterRules(castx.ToStringMap(value))
+	}
+
+	ipFilterMu.Lock()
+	ipFilterRules = rules
+	ipFilterMu.Unlock()
+}
+
+// GetIpFilterRules returns the rule set of name, it is loaded from ipFilter.<name> in AppConf on first use
+// when not registered, nil when the rule set is not defined
+func GetIpFilterRules(name string) *IpFilterRules {
+	ipFilterMu.RLock()
+	rules, ok := ipFilterRules[name]
+	ipFilterMu.RUnlock()
+
+	if ok {
+		return rules
+	}
+
+	settings := AppConf.GetMap("ipFilter." + name)
+
+	if len(settings) < 1 {
+		return nil
+	}
+
+	ipFilterMu.Lock()
+	defer ipFilterMu.Unlock()
+
+	if rules, ok := ipFilterRules[name]; ok {
+		return rules
+	}
+
+	rules = NewIpFilterRules(settings)
+	ipFilterRules[name] = rules
+	return rules
+}
+
+func WithCountryLookup(lookup CountryLookup) {
+	countryLookup = lookup
+}
+
+func GetCountryLookup() CountryLookup {
+	return countryLookup
+}
+
+func LookupCountry(ip net.IP) string {
+	if countryLookup == nil || ip == nil {
+		return ""
+	}
+
+	country, err := countryLookup.LookupCountry(ip)
+
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToUpper(country)
+}
@@ -1,10 +1,18 @@
 package mgboot
 
-import "github.com/meiguonet/mgboot-go-common/logx"
+import (
+	"github.com/meiguonet/mgboot-go-common/logx"
+	"strings"
+	"time"
+)
 
 var runtimeLogger logx.Logger
 var requestLogLogger logx.Logger
 var logRequestBody bool
+var logRequestHeaders bool
+var logResponseBody bool
+var responseLogContentTypes = []string{"application/json", "application/xml", "text/*"}
+var slowRequestThreshold time.Duration
 var executeTimeLogLogger logx.Logger
 
 func RuntimeLogger(logger ...logx.Logger) logx.Logger {
@@ -47,6 +55,48 @@ func LogRequestBody(flag ...bool) bool {
 	return logRequestBody
 }
 
+func LogRequestHeaders(flag ...bool) bool {
+	if len(flag) > 0 {
+		logRequestHeaders = flag[0]
+	}
+
+	return logRequestHeaders
+}
+
+func LogResponseBody(flag ...bool) bool {
+	if len(flag) > 0 {
+		logResponseBody = flag[0]
+	}
+
+	return logResponseBody
+}
+
+// ResponseLogContentTypes limits which responses have their body logged, text/* style wildcards are supported
+func ResponseLogContentTypes(contentTypes ...string) []string {
+	if len(contentTypes) > 0 {
+		types := make([]string, 0, len(contentTypes))
+
+		for _, s1 := range contentTypes {
+			if s1 = strings.ToLower(strings.TrimSpace(s1)); s1 != "" {
+				types = append(types, s1)
+			}
+		}
+
+		responseLogContentTypes = types
+	}
+
+	return responseLogContentTypes
+}
+
+// SlowRequestThreshold requests taking longer are logged in full through ExecuteTimeLogLogger, 0 disables it
+func SlowRequestThreshold(threshold ...time.Duration) time.Duration {
+	if len(threshold) > 0 && threshold[0] >= 0 {
+		slowRequestThreshold = threshold[0]
+	}
+
+	return slowRequestThreshold
+}
+
 func ExecuteTimeLogLogger(logger ...logx.Logger) logx.Logger {
 	if len(logger) > 0 {
 		executeTimeLogLogger = logger[0]
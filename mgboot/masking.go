@@ -0,0 +1,389 @@
+package mgboot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type MaskDetector struct {
+	re     *regexp.Regexp
+	maskFn func(match string) string
+}
+
+var maskSettings *MaskSettings
+var maskDetectors = map[string]*MaskDetector{}
+var maskDetectorsMu = &sync.RWMutex{}
+
+func init() {
+	maskDetectors["phone"] = &MaskDetector{
+		re: regexp.MustCompile(`\b(?:\+?86[- ]?)?1[3-9]\d{9}\b`),
+		maskFn: func(match string) string {
+			return keepEnds(match, 3, 4)
+		},
+	}
+
+	maskDetectors["idcard"] = &MaskDetector{
+		re: regexp.MustCompile(`\b[1-9]\d{5}(?:18|19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]\b`),
+		maskFn: func(match string) string {
+			return keepEnds(match, 3, 4)
+		},
+	}
+
+	maskDetectors["bankcard"] = &MaskDetector{
+		re: regexp.MustCompile(`\b[1-9]\d{15,18}\b`),
+		maskFn: func(match string) string {
+			if !luhnValid(match) {
+				return match
+			}
+
+			return keepEnds(match, 0, 4)
+		},
+	}
+
+	maskDetectors["email"] = &MaskDetector{
+		re: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		maskFn: func(match string) string {
+			idx := strings.Index(match, "@")
+			return keepEnds(match[:idx], 1, 0) + match[idx:]
+		},
+	}
+}
+
+func WithMaskSettings(settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("logging.mask")
+	}
+
+	maskSettings = NewMaskSettings(_settings)
+}
+
+func GetMaskSettings() *MaskSettings {
+	if maskSettings == nil {
+		return NewMaskSettings(map[string]interface{}{})
+	}
+
+	return maskSettings
+}
+
+// WithMaskDetector registers or replaces a named detector, name it in the detectors setting to enable it
+func WithMaskDetector(name string, re *regexp.Regexp, maskFn ...func(match string) string) {
+	var fn func(match string) string
+
+	if len(maskFn) > 0 && maskFn[0] != nil {
+		fn = maskFn[0]
+	} else {
+		fn = func(match string) string {
+			return keepEnds(match, 0, 0)
+		}
+	}
+
+	maskDetectorsMu.Lock()
+	maskDetectors[name] = &MaskDetector{re: re, maskFn: fn}
+	maskDetectorsMu.Unlock()
+}
+
+// MaskText replaces everything the enabled detectors recognize in s1
+func MaskText(s1 string, settings ...*MaskSettings) string {
+	st := maskSettingsOf(settings)
+
+	if s1 == "" {
+		return s1
+	}
+
+	maskDetectorsMu.RLock()
+	defer maskDetectorsMu.RUnlock()
+
+	for _, name := range st.Detectors() {
+		detector, ok := maskDetectors[name]
+
+		if !ok {
+			continue
+		}
+
+		s1 = detector.re.ReplaceAllStringFunc(s1, detector.maskFn)
+	}
+
+	return s1
+}
+
+// MaskBody masks fields and detector matches in a json, xml, form or plain text body
+// and truncates the result to the max body size
+func MaskBody(body []byte, contentType string, settings ...*MaskSettings) string {
+	st := maskSettingsOf(settings)
+
+	if len(body) < 1 {
+		return ""
+	}
+
+	maxBodySize := int(st.MaxBodySize())
+	var truncated int
+
+	if maxBodySize > 0 && len(body) > maxBodySize {
+		truncated = len(body)
+		body = body[:maxBodySize]
+	}
+
+	contentType = strings.ToLower(contentType)
+	var contents string
+
+	switch {
+	case truncated < 1 && strings.Contains(contentType, "json"):
+		contents = maskJsonBody(body, st)
+	case strings.Contains(contentType, fiber.MIMEApplicationForm):
+		contents = maskFormBody(string(body), st)
+	default:
+		contents = maskPlainBody(string(body), st)
+	}
+
+	if truncated > 0 {
+		contents += fmt.Sprintf("...(truncated, %d bytes total)", truncated)
+	}
+
+	return contents
+}
+
+func MaskRequestBody(ctx *fiber.Ctx, settings ...*MaskSettings) string {
+	contentType := ctx.Get(fiber.HeaderContentType)
+
+	// GetRawBody returns multipart form values url encoded
+	if strings.Contains(strings.ToLower(contentType), fiber.MIMEMultipartForm) {
+		contentType = fiber.MIMEApplicationForm
+	}
+
+	return MaskBody(GetRawBody(ctx), contentType, settings...)
+}
+
+func MaskResponseBody(ctx *fiber.Ctx, settings ...*MaskSettings) string {
+	resp := ctx.Response()
+	contentType := string(resp.Header.ContentType())
+
+	if !isLoggableContentType(contentType) {
+		return ""
+	}
+
+	if resp.IsBodyStream() {
+		return "(stream)"
+	}
+
+	if encoding := string(resp.Header.Peek(fiber.HeaderContentEncoding)); encoding != "" {
+		return fmt.Sprintf("(%d bytes, %s encoded)", len(resp.Body()), encoding)
+	}
+
+	return MaskBody(resp.Body(), contentType, settings...)
+}
+
+func MaskHeaderValue(name, value string, settings ...*MaskSettings) string {
+	st := maskSettingsOf(settings)
+
+	if !st.IsMaskedHeader(name) {
+		return MaskText(value, st)
+	}
+
+	if strings.EqualFold(name, fiber.HeaderCookie) || strings.EqualFold(name, fiber.HeaderSetCookie) {
+		parts := strings.Split(value, ";")
+
+		for i, part := range parts {
+			if idx := strings.Index(part, "="); idx > 0 && (i == 0 || strings.EqualFold(name, fiber.HeaderCookie)) {
+				parts[i] = part[:idx+1] + st.Mask()
+			}
+		}
+
+		return strings.Join(parts, ";")
+	}
+
+	if idx := strings.Index(value, " "); idx > 0 {
+		return value[:idx+1] + st.Mask()
+	}
+
+	return st.Mask()
+}
+
+func MaskRequestHeaders(ctx *fiber.Ctx, settings ...*MaskSettings) map[string]string {
+	st := maskSettingsOf(settings)
+	headers := map[string]string{}
+
+	ctx.Request().Header.VisitAll(func(key, value []byte) {
+		name := string(key)
+		headers[name] = MaskHeaderValue(name, string(value), st)
+	})
+
+	return headers
+}
+
+func MaskResponseHeaders(ctx *fiber.Ctx, settings ...*MaskSettings) map[string]string {
+	st := maskSettingsOf(settings)
+	headers := map[string]string{}
+
+	ctx.Response().Header.VisitAll(func(key, value []byte) {
+		name := string(key)
+		headers[name] = MaskHeaderValue(name, string(value), st)
+	})
+
+	return headers
+}
+
+func maskSettingsOf(settings []*MaskSettings) *MaskSettings {
+	if len(settings) > 0 && settings[0] != nil {
+		return settings[0]
+	}
+
+	return GetMaskSettings()
+}
+
+func maskRequestUri(ctx *fiber.Ctx, settings ...*MaskSettings) string {
+	uri := string(ctx.Request().RequestURI())
+	idx := strings.Index(uri, "?")
+
+	if idx < 0 {
+		return uri
+	}
+
+	return uri[:idx+1] + maskFormBody(uri[idx+1:], maskSettingsOf(settings))
+}
+
+func maskJsonBody(body []byte, st *MaskSettings) string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var data interface{}
+
+	if err := decoder.Decode(&data); err != nil {
+		return maskPlainBody(string(body), st)
+	}
+
+	buf, err := json.Marshal(maskJsonValue(data, nil, st))
+
+	if err != nil {
+		return maskPlainBody(string(body), st)
+	}
+
+	return string(buf)
+}
+
+func maskJsonValue(value interface{}, path []string, st *MaskSettings) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			p := append(append(make([]string, 0, len(path)+1), path...), strings.ToLower(key))
+
+			if st.IsMaskedField(key, p) {
+				v[key] = st.Mask()
+				continue
+			}
+
+			v[key] = maskJsonValue(item, p, st)
+		}
+
+		return v
+	case []interface{}:
+		for i, item := range v {
+			p := append(append(make([]string, 0, len(path)+1), path...), strconv.Itoa(i))
+
+			if st.IsMaskedField("", p) {
+				v[i] = st.Mask()
+				continue
+			}
+
+			v[i] = maskJsonValue(item, p, st)
+		}
+
+		return v
+	case string:
+		return MaskText(v, st)
+	case json.Number:
+		if s1 := MaskText(v.String(), st); s1 != v.String() {
+			return s1
+		}
+
+		return v
+	default:
+		return v
+	}
+}
+
+func maskFormBody(body string, st *MaskSettings) string {
+	values, err := url.ParseQuery(body)
+
+	if err != nil {
+		return maskPlainBody(body, st)
+	}
+
+	keys := make([]string, 0, len(values))
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	sb := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		masked := st.IsMaskedField(key, []string{strings.ToLower(key)})
+
+		for _, value := range values[key] {
+			if masked {
+				value = st.Mask()
+			} else {
+				value = MaskText(value, st)
+			}
+
+			sb = append(sb, key+"="+value)
+		}
+	}
+
+	return strings.Join(sb, "&")
+}
+
+func maskPlainBody(body string, st *MaskSettings) string {
+	for _, re := range st.patterns {
+		body = re.ReplaceAllString(body, "${1}"+st.Mask()+"${2}")
+	}
+
+	return MaskText(body, st)
+}
+
+func keepEnds(s1 string, head, tail int) string {
+	runes := []rune(s1)
+
+	if head+tail >= len(runes) {
+		return strings.Repeat("*", len(runes))
+	}
+
+	return string(runes[:head]) + strings.Repeat("*", len(runes)-head-tail) + string(runes[len(runes)-tail:])
+}
+
+func luhnValid(digits string) bool {
+	var sum int
+	double := false
+
+	for i := len(digits) - 1; i >= 0; i-- {
+		n1 := int(digits[i] - '0')
+
+		if double {
+			n1 *= 2
+
+			if n1 > 9 {
+				n1 -= 9
+			}
+		}
+
+		sum += n1
+		double = !double
+	}
+
+	return sum%10 == 0
+}
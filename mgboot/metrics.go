@@ -0,0 +1,177 @@
+package mgboot
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-fiber/cachex"
+	"github.com/meiguonet/mgboot-go-fiber/enum/JwtVerifyErrno"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+	"strconv"
+	"sync"
+)
+
+var metricsRegistry *prometheus.Registry
+var metricsNamespace string
+var metricsMu = &sync.RWMutex{}
+var httpRequestsTotal *prometheus.CounterVec
+var httpRequestDuration *prometheus.HistogramVec
+var rateLimitRejectionsTotal *prometheus.CounterVec
+var jwtAuthFailuresTotal *prometheus.CounterVec
+
+// WithMetricsSettings creates the metrics registry, supported keys:
+// enabled, namespace, buckets, runtimeMetrics
+func WithMetricsSettings(settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("metrics")
+	}
+
+	if !castx.ToBool(_settings["enabled"]) {
+		return
+	}
+
+	namespace := castx.ToString(_settings["namespace"])
+
+	if namespace == "" {
+		namespace = "mgboot"
+	}
+
+	buckets := make([]float64, 0)
+
+	for _, item := range castx.ToSlice(_settings["buckets"]) {
+		if n1, err := castx.ToFloat64E(item); err == nil && n1 > 0 {
+			buckets = append(buckets, n1)
+		}
+	}
+
+	if len(buckets) < 1 {
+		buckets = prometheus.DefBuckets
+	}
+
+	registry := prometheus.NewRegistry()
+
+	if _, ok := _settings["runtimeMetrics"]; !ok || castx.ToBool(_settings["runtimeMetrics"]) {
+		registry.MustRegister(prometheus.NewGoCollector())
+		registry.MustRegister(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+	}
+
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Total number of http requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Http request latency by method, route and status.",
+		Buckets:   buckets,
+	}, []string{"method", "route", "status"})
+
+	rateLimitRejectionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ratelimit_rejections_total",
+		Help:      "Total number of requests rejected by the rate limiter.",
+	}, []string{"handler"})
+
+	jwtAuthFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "jwt_auth_failures_total",
+		Help:      "Total number of failed jwt authentications by errno.",
+	}, []string{"errno"})
+
+	registry.MustRegister(httpRequestsTotal, httpRequestDuration, rateLimitRejectionsTotal, jwtAuthFailuresTotal)
+	registry.MustRegister(cachex.NewMetricsCollector(namespace))
+
+	metricsMu.Lock()
+	metricsRegistry = registry
+	metricsNamespace = namespace
+	metricsMu.Unlock()
+}
+
+func MetricsEnabled() bool {
+	return MetricsRegistry() != nil
+}
+
+func MetricsRegistry() *prometheus.Registry {
+	metricsMu.RLock()
+	defer metricsMu.RUnlock()
+	return metricsRegistry
+}
+
+func MetricsNamespace() string {
+	metricsMu.RLock()
+	defer metricsMu.RUnlock()
+	return metricsNamespace
+}
+
+func WithMetricsCollector(collector prometheus.Collector) error {
+	registry := MetricsRegistry()
+
+	if registry == nil {
+		return nil
+	}
+
+	return registry.Register(collector)
+}
+
+func MetricsHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		registry := MetricsRegistry()
+
+		if registry == nil {
+			return ctx.SendStatus(fiber.StatusNotFound)
+		}
+
+		handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+		fasthttpadaptor.NewFastHTTPHandler(handler)(ctx.Context())
+		return nil
+	}
+}
+
+func ObserveRateLimitRejection(handlerName string) {
+	if !MetricsEnabled() {
+		return
+	}
+
+	rateLimitRejectionsTotal.WithLabelValues(handlerName).Inc()
+}
+
+func ObserveJwtAuthFailure(errno int) {
+	if !MetricsEnabled() {
+		return
+	}
+
+	jwtAuthFailuresTotal.WithLabelValues(jwtVerifyErrnoLabel(errno)).Inc()
+}
+
+func observeHttpRequest(method, route string, statusCode int, seconds float64) {
+	if !MetricsEnabled() {
+		return
+	}
+
+	status := strconv.Itoa(statusCode)
+	httpRequestsTotal.WithLabelValues(method, route, status).Inc()
+	httpRequestDuration.WithLabelValues(method, route, status).Observe(seconds)
+}
+
+func jwtVerifyErrnoLabel(errno int) string {
+	switch errno {
+	case JwtVerifyErrno.NotFound:
+		return "not_found"
+	case JwtVerifyErrno.Invalid:
+		return "invalid"
+	case JwtVerifyErrno.Expired:
+		return "expired"
+	default:
+		return strconv.Itoa(errno)
+	}
+}
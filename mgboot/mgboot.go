@@ -34,13 +34,19 @@ func LogExecuteTime(ctx *fiber.Ctx) {
 		return
 	}
 
+	ctx.Set("X-Response-Time", elapsedTime)
+
+	// the access log already records latency in its single per-request entry
+	if IsAccessLogged(ctx) {
+		return
+	}
+
 	sb := strings.Builder{}
 	sb.WriteString(ctx.Method())
 	sb.WriteString(" ")
 	sb.WriteString(GetRequestUrl(ctx, true))
 	sb.WriteString(", total elapsed time: " + elapsedTime)
-	ExecuteTimeLogLogger().Info(sb.String())
-	ctx.Set("X-Response-Time", elapsedTime)
+	NewContextLogger(ExecuteTimeLogLogger(), ctx).Info(sb.String())
 }
 
 func WithBuiltinErrorHandlers() {
@@ -48,6 +54,10 @@ func WithBuiltinErrorHandlers() {
 		NewRateLimitErrorHandler(),
 		NewJwtAuthErrorHandler(),
 		NewValidateErrorHandler(),
+		NewAccessDeniedErrorHandler(),
+		NewBodyLimitErrorHandler(),
+		NewUploadErrorHandler(),
+		NewRequestTimeoutErrorHandler(),
 	}
 }
 
@@ -167,6 +177,7 @@ func RateLimitCheck(ctx *fiber.Ctx, handlerName string, settings interface{}) er
 	remaining := castx.ToInt(result["remaining"])
 
 	if remaining < 0 {
+		ObserveRateLimitRejection(handlerName)
 		return NewRateLimitError(result)
 	}
 
@@ -184,6 +195,7 @@ func JwtAuthCheck(ctx *fiber.Ctx, settingsKey string) error {
 		return nil
 	}
 
+	ctx.Locals("JwtSettingsKey", settingsKey)
 	token := strings.TrimSpace(ctx.Get(fiber.HeaderAuthorization))
 	token = stringx.RegexReplace(token, RegexConst.SpaceSep, " ")
 
@@ -192,12 +204,14 @@ func JwtAuthCheck(ctx *fiber.Ctx, settingsKey string) error {
 	}
 
 	if token == "" {
+		ObserveJwtAuthFailure(JwtVerifyErrno.NotFound)
 		return NewJwtAuthError(JwtVerifyErrno.NotFound)
 	}
 
 	errno := VerifyJsonWebToken(token, settings)
 
 	if errno < 0 {
+		ObserveJwtAuthFailure(errno)
 		return NewJwtAuthError(errno)
 	}
 
@@ -268,89 +282,24 @@ func ValidateCheck(ctx *fiber.Ctx, settings interface{}) error {
 }
 
 func CheckUploadedFile(fh *multipart.FileHeader, opts map[string]interface{}) (passed bool, errorTips string) {
-	if fh == nil {
-		errorTips = "没有文件被上传"
-		return
-	}
-
-	var maxFileSize int64
-
-	if s1, ok := opts["fileSizeLimit"]; ok && s1 != "" {
-		maxFileSize = castx.ToDataSize(s1)
-	}
-
-	if maxFileSize > 0 && fh.Size > maxFileSize {
-		errorTips = "文件大小超出限制"
-		return
-	}
-
-	if !castx.ToBool(opts["checkImage"]) {
-		return
-	}
-
-	var fn ImageInfoGetFunc
-
-	if f1, ok := opts["imageInfoFunc"].(ImageInfoGetFunc); ok {
-		fn = f1
-	}
-
-	if fn == nil {
-		return
-	}
-
-	map1 := fn(fh)
-	width := castx.ToInt(map1["width"])
-	height := castx.ToInt(map1["height"])
-	mimeType := castx.ToString(map1["mimeType"])
-
-	if width < 1 || height < 1 || mimeType == "" {
-		errorTips = "不是有效的图片文件"
-		return
-	}
-
-	imageSizeLimit := castx.ToString(opts["imageSizeLimit"])
-
-	if imageSizeLimit != "" {
-		var n1 int
-		var n2 int
-		parts := stringx.SplitWithRegexp(strings.TrimSpace(imageSizeLimit), `[\x20\t]*x[\x20\t]*`)
-
-		if len(parts) >= 2 {
-			n1 = castx.ToInt(parts[0])
-			n2 = castx.ToInt(parts[1])
+	if err := ValidateUploadedFile(fh, opts); err != nil {
+		if ex, ok := err.(UploadError); ok {
+			return false, ex.Message()
 		}
 
-		if n1 > 0 && n2 > 0 && (width != n1 || height != n2) {
-			errorTips = fmt.Sprintf("请上传%dx%d的图片", n1, n2)
-			return
-		}
+		return false, err.Error()
 	}
 
-	imageRatioLimit := castx.ToString(opts["imageRatioLimit"])
-
-	if imageRatioLimit != "" {
-		var n1 int
-		var n2 int
-		parts := stringx.SplitWithRegexp(strings.TrimSpace(imageRatioLimit), `[\x20\t]*:[\x20\t]*`)
-
-		if len(parts) >= 2 {
-			n1 = castx.ToInt(parts[0])
-			n2 = castx.ToInt(parts[1])
-		}
-
-		if n1 > 0 && n2 > 0 {
-			n3 := numberx.Ojld(width, height)
-			n4 := width / n3
-			n5 := height / n3
+	return true, ""
+}
 
-			if n4 != n1 || n5 != n2 {
-				errorTips = fmt.Sprintf("请上传%d:%d比例的图片", n1, n2)
-				return
-			}
-		}
+// ToResponsePayload returns payload itself when it is a ResponsePayload, otherwise payload is wrapped as JsonResponse
+func ToResponsePayload(payload interface{}) ResponsePayload {
+	if pl, ok := payload.(ResponsePayload); ok {
+		return pl
 	}
 
-	return
+	return NewJsonResponse(payload)
 }
 
 func SendOutput(ctx *fiber.Ctx, payload ResponsePayload, err error) error {
@@ -377,6 +326,8 @@ func SendOutput(ctx *fiber.Ctx, payload ResponsePayload, err error) error {
 		return nil
 	}
 
+	ctx.Locals("ResponsePayload", payload)
+
 	if pl, ok := payload.(AttachmentResponse); ok {
 		pl.AddSpecifyHeaders(ctx)
 		ctx.Send(pl.Buffer())
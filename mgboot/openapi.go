@@ -0,0 +1,656 @@
+package mgboot
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"html/template"
+	"io/fs"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// the pinned ui bundles are fetched into assets/openapi/dist by go generate
+//go:generate go run assets/openapi/fetch.go
+
+//go:embed assets/openapi/*.html assets/openapi/dist
+var openApiAssets embed.FS
+
+var openApiSettings *OpenApiSettings
+var openApiPages = template.Must(template.ParseFS(openApiAssets, "assets/openapi/*.html"))
+var openApiPathParam = regexp.MustCompile(`:([A-Za-z0-9_]+)\??`)
+var openApiPathTemplate = regexp.MustCompile(`\{([^}]+)}`)
+
+// the payloads of the builtin error handlers, all of them are sent with status 200
+var openApiErrorCodes = []struct {
+	code    int
+	msg     string
+	example string
+}{
+	{1001, "安全令牌缺失", "安全令牌缺失"},
+	{1002, "不是有效的安全令牌", "不是有效的安全令牌"},
+	{1003, "安全令牌已失效", "安全令牌已失效"},
+	{1006, "数据验证失败，msg 为各字段的错误信息，failfast 时为第一条错误信息", `{"name":"必须填写"}`},
+}
+
+func WithOpenApiSettings(settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("openapi")
+	}
+
+	openApiSettings = NewOpenApiSettings(_settings)
+}
+
+func GetOpenApiSettings() *OpenApiSettings {
+	if openApiSettings == nil {
+		return NewOpenApiSettings(map[string]interface{}{})
+	}
+
+	return openApiSettings
+}
+
+// BuildOpenApiDoc generates an openapi 3.1 document from the routes of router, the dto of a route
+// is declared by Route.SetInput and its validate tags become schema constraints
+func BuildOpenApiDoc(router *Router, settings ...*OpenApiSettings) map[string]interface{} {
+	var st *OpenApiSettings
+
+	if len(settings) > 0 && settings[0] != nil {
+		st = settings[0]
+	} else {
+		st = GetOpenApiSettings()
+	}
+
+	info := map[string]interface{}{
+		"title":   st.Title(),
+		"version": st.Version(),
+	}
+
+	if st.Description() != "" {
+		info["description"] = st.Description()
+	}
+
+	doc := map[string]interface{}{
+		"openapi": "3.1.0",
+		"info":    info,
+	}
+
+	if len(st.Servers()) > 0 {
+		servers := make([]interface{}, 0, len(st.Servers()))
+
+		for _, s1 := range st.Servers() {
+			servers = append(servers, map[string]interface{}{"url": s1})
+		}
+
+		doc["servers"] = servers
+	}
+
+	builder := newOpenApiSchemaBuilder()
+	securitySchemes := map[string]interface{}{}
+	paths := map[string]interface{}{}
+	operationIds := map[string]bool{}
+	tagSet := map[string]bool{}
+	var usesErrorPayload bool
+
+	for _, route := range router.Routes() {
+		op, errorCodes := buildOpenApiOperation(builder, route, securitySchemes)
+		op["operationId"] = openApiOperationId(route, operationIds)
+
+		if len(errorCodes) > 0 {
+			usesErrorPayload = true
+		}
+
+		for _, tag := range route.Tags() {
+			tagSet[tag] = true
+		}
+
+		key := openApiPath(route.Path())
+		item, ok := paths[key].(map[string]interface{})
+
+		if !ok {
+			item = map[string]interface{}{}
+			paths[key] = item
+		}
+
+		item[strings.ToLower(route.Method())] = op
+	}
+
+	doc["paths"] = paths
+
+	if len(tagSet) > 0 {
+		names := make([]string, 0, len(tagSet))
+
+		for name := range tagSet {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+		tags := make([]interface{}, 0, len(names))
+
+		for _, name := range names {
+			tags = append(tags, map[string]interface{}{"name": name})
+		}
+
+		doc["tags"] = tags
+	}
+
+	if usesErrorPayload {
+		builder.schemas["ErrorPayload"] = openApiErrorPayloadSchema()
+	}
+
+	components := map[string]interface{}{}
+
+	if len(builder.schemas) > 0 {
+		components["schemas"] = builder.schemas
+	}
+
+	if len(securitySchemes) > 0 {
+		components["securitySchemes"] = securitySchemes
+	}
+
+	if len(components) > 0 {
+		doc["components"] = components
+	}
+
+	return doc
+}
+
+// OpenApiHandler serves the document as json, it is generated again only when routes are added
+func OpenApiHandler(router *Router, settings ...*OpenApiSettings) fiber.Handler {
+	mu := &sync.Mutex{}
+	var contents string
+	var routeCount = -1
+
+	return func(ctx *fiber.Ctx) error {
+		mu.Lock()
+
+		if n1 := len(router.Routes()); n1 != routeCount {
+			contents = strings.TrimSpace(jsonx.ToJson(BuildOpenApiDoc(router, settings...)))
+			routeCount = n1
+		}
+
+		s1 := contents
+		mu.Unlock()
+		ctx.Type("json", "utf8")
+		return ctx.SendString(s1)
+	}
+}
+
+func SwaggerUiHandler(docUrl string, settings ...*OpenApiSettings) fiber.Handler {
+	return openApiPageHandler("swagger-ui.html", docUrl, settings...)
+}
+
+func RedocHandler(docUrl string, settings ...*OpenApiSettings) fiber.Handler {
+	return openApiPageHandler("redoc.html", docUrl, settings...)
+}
+
+func openApiPageHandler(page, docUrl string, settings ...*OpenApiSettings) fiber.Handler {
+	var st *OpenApiSettings
+
+	if len(settings) > 0 && settings[0] != nil {
+		st = settings[0]
+	} else {
+		st = GetOpenApiSettings()
+	}
+
+	scriptFile := "swagger-ui-bundle.js"
+
+	if page == "redoc.html" {
+		scriptFile = "redoc.standalone.js"
+	}
+
+	var assetsUrl string
+
+	switch {
+	case st.AssetsDir() != "" || (!st.CdnEnabled() && openApiBundleEmbedded(scriptFile)):
+		assetsUrl = openApiAssetsUrl(docUrl, st)
+
+		if page == "redoc.html" {
+			assetsUrl += "/" + scriptFile
+		}
+	case page == "redoc.html":
+		assetsUrl = st.RedocUrl()
+		scriptFile = path.Base(assetsUrl)
+	default:
+		assetsUrl = st.SwaggerUiUrl()
+	}
+
+	buf := &bytes.Buffer{}
+
+	err := openApiPages.ExecuteTemplate(buf, page, map[string]interface{}{
+		"Title":           st.Title(),
+		"DocUrl":          docUrl,
+		"AssetsUrl":       assetsUrl,
+		"ScriptIntegrity": st.Integrity(scriptFile),
+		"StyleIntegrity":  st.Integrity("swagger-ui.css"),
+	})
+
+	if err != nil {
+		err = fmt.Errorf("in mgboot.openApiPageHandler, failed to render %s: %v", page, err)
+
+		return func(ctx *fiber.Ctx) error {
+			return SendOutput(ctx, nil, err)
+		}
+	}
+
+	contents := buf.String()
+
+	return func(ctx *fiber.Ctx) error {
+		ctx.Type("html", "utf8")
+		return ctx.SendString(contents)
+	}
+}
+
+// OpenApiAssetsHandler serves the embedded ui bundles, the file name is read from the file route param
+func OpenApiAssetsHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		fileName := path.Base(ctx.Params("file"))
+		buf, err := openApiAssets.ReadFile("assets/openapi/dist/" + fileName)
+
+		if err != nil {
+			return ctx.SendStatus(fiber.StatusNotFound)
+		}
+
+		ctx.Type(strings.TrimPrefix(path.Ext(fileName), "."), "utf8")
+		ctx.Set(fiber.HeaderCacheControl, "public, max-age=86400")
+		return ctx.Send(buf)
+	}
+}
+
+func openApiBundleEmbedded(fileName string) bool {
+	_, err := fs.Stat(openApiAssets, "assets/openapi/dist/"+fileName)
+	return err == nil
+}
+
+// openApiAssetsUrl is the url of the ui bundles, it sits under the ui path next to docUrl
+func openApiAssetsUrl(docUrl string, st *OpenApiSettings) string {
+	prefix := strings.TrimSuffix(docUrl, st.DocPath())
+	return joinRoutePath(prefix, st.UiPath()) + "/assets"
+}
+
+func buildOpenApiOperation(
+	builder *openApiSchemaBuilder,
+	route *Route,
+	securitySchemes map[string]interface{},
+) (map[string]interface{}, []int) {
+	op := map[string]interface{}{}
+
+	if route.Summary() != "" {
+		op["summary"] = route.Summary()
+	}
+
+	if route.Description() != "" {
+		op["description"] = route.Description()
+	}
+
+	if len(route.Tags()) > 0 {
+		op["tags"] = route.Tags()
+	}
+
+	if route.Deprecated() {
+		op["deprecated"] = true
+	}
+
+	method := route.Method()
+	hasBody := method == fiber.MethodPost || method == fiber.MethodPut || method == fiber.MethodPatch || method == fiber.MethodDelete
+	params := make([]*openApiParam, 0)
+	bodySchemas := make([]interface{}, 0)
+	contentTypes := []string{fiber.MIMEApplicationJSON}
+	errorCodes := make([]int, 0)
+
+	if dtoType := openApiInputType(route.Input()); dtoType != nil {
+		errorCodes = append(errorCodes, 1006)
+		params = append(params, builder.params(dtoType, hasBody)...)
+		hasFields, hasForm, hasFiles := builder.hasBodyFields(dtoType)
+
+		if hasBody && hasFields {
+			bodySchemas = append(bodySchemas, builder.objectSchema(dtoType, true))
+
+			if hasFiles {
+				contentTypes = []string{fiber.MIMEMultipartForm}
+			} else if hasForm {
+				contentTypes = []string{fiber.MIMEApplicationForm, fiber.MIMEMultipartForm}
+			}
+		}
+	}
+
+	for _, p := range route.Policies() {
+		switch p.PolicyKind() {
+		case "JwtAuth":
+			args := policyArgs(p)
+
+			if len(args) < 1 {
+				continue
+			}
+
+			name := openApiNameSanitizer.ReplaceAllString("jwt_"+args[0], "_")
+
+			securitySchemes[name] = map[string]interface{}{
+				"type":         "http",
+				"scheme":       "bearer",
+				"bearerFormat": "JWT",
+				"description":  fmt.Sprintf("Authorization: Bearer <token>, verified by jwt settings %s", args[0]),
+			}
+
+			op["security"] = []interface{}{map[string]interface{}{name: []string{}}}
+			errorCodes = append(errorCodes, 1001, 1002, 1003)
+		case "Validate":
+			rules := policyArgs(p)
+
+			if len(rules) < 1 {
+				continue
+			}
+
+			errorCodes = append(errorCodes, 1006)
+			schema := validatePolicySchema(rules)
+
+			if hasBody {
+				bodySchemas = append(bodySchemas, schema)
+				continue
+			}
+
+			properties, _ := schema["properties"].(map[string]interface{})
+			required, _ := schema["required"].([]string)
+
+			names := make([]string, 0, len(properties))
+
+			for name := range properties {
+				names = append(names, name)
+			}
+
+			sort.Strings(names)
+
+			for _, name := range names {
+				params = append(params, &openApiParam{
+					name:     name,
+					in:       "query",
+					required: openApiContains(required, name),
+					schema:   properties[name].(map[string]interface{}),
+				})
+			}
+		}
+	}
+
+	params = mergeOpenApiPathParams(route.Path(), params)
+
+	if len(params) > 0 {
+		items := make([]interface{}, 0, len(params))
+
+		for _, p := range params {
+			item := map[string]interface{}{
+				"name":   p.name,
+				"in":     p.in,
+				"schema": p.schema,
+			}
+
+			if p.required {
+				item["required"] = true
+			}
+
+			if desc, ok := p.schema["description"]; ok {
+				item["description"] = desc
+			}
+
+			items = append(items, item)
+		}
+
+		op["parameters"] = items
+	}
+
+	if len(bodySchemas) > 0 {
+		var schema interface{} = bodySchemas[0]
+
+		if len(bodySchemas) > 1 {
+			schema = map[string]interface{}{"allOf": bodySchemas}
+		}
+
+		content := map[string]interface{}{}
+
+		for _, contentType := range contentTypes {
+			content[contentType] = map[string]interface{}{"schema": schema}
+		}
+
+		op["requestBody"] = map[string]interface{}{"required": true, "content": content}
+	}
+
+	errorCodes = uniqueOpenApiCodes(errorCodes)
+	op["responses"] = buildOpenApiResponses(builder, route, errorCodes)
+	return op, errorCodes
+}
+
+func buildOpenApiResponses(builder *openApiSchemaBuilder, route *Route, errorCodes []int) map[string]interface{} {
+	var success interface{}
+
+	if route.Output() != nil {
+		success = builder.schemaOf(reflect.TypeOf(route.Output()))
+	}
+
+	ok := map[string]interface{}{"description": "成功"}
+
+	if len(errorCodes) > 0 {
+		if success == nil {
+			success = map[string]interface{}{}
+		}
+
+		examples := map[string]interface{}{}
+
+		for _, item := range openApiErrorCodes {
+			if !openApiContainsCode(errorCodes, item.code) {
+				continue
+			}
+
+			examples[fmt.Sprintf("error%d", item.code)] = map[string]interface{}{
+				"summary": fmt.Sprintf("%d %s", item.code, item.msg),
+				"value":   map[string]interface{}{"code": item.code, "msg": item.example, "data": nil},
+			}
+		}
+
+		ok["content"] = map[string]interface{}{
+			fiber.MIMEApplicationJSON: map[string]interface{}{
+				"schema": map[string]interface{}{
+					"anyOf": []interface{}{success, map[string]interface{}{"$ref": "#/components/schemas/ErrorPayload"}},
+				},
+				"examples": examples,
+			},
+		}
+	} else if success != nil {
+		ok["content"] = map[string]interface{}{
+			fiber.MIMEApplicationJSON: map[string]interface{}{"schema": success},
+		}
+	}
+
+	responses := map[string]interface{}{"200": ok}
+
+	if openApiHasRateLimit(route) {
+		responses["429"] = map[string]interface{}{"description": "请求过于频繁"}
+	}
+
+	if route.HasPolicy("Timeout") {
+		responses["503"] = map[string]interface{}{"description": "请求处理超时"}
+	}
+
+	return responses
+}
+
+func openApiErrorPayloadSchema() map[string]interface{} {
+	codes := make([]interface{}, 0, len(openApiErrorCodes))
+	lines := make([]string, 0, len(openApiErrorCodes))
+
+	for _, item := range openApiErrorCodes {
+		codes = append(codes, item.code)
+		lines = append(lines, fmt.Sprintf("%d: %s", item.code, item.msg))
+	}
+
+	return map[string]interface{}{
+		"type":        "object",
+		"description": "内置错误处理器的响应，http 状态码为 200\n\n" + strings.Join(lines, "\n\n"),
+		"required":    []string{"code", "msg"},
+		"properties": map[string]interface{}{
+			"code": map[string]interface{}{"type": "integer", "enum": codes},
+			"msg":  map[string]interface{}{"type": "string"},
+			"data": map[string]interface{}{},
+		},
+	}
+}
+
+func openApiInputType(dto interface{}) reflect.Type {
+	if dto == nil {
+		return nil
+	}
+
+	rt := reflect.TypeOf(dto)
+
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	if rt.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return rt
+}
+
+// openApiPath turns fiber style /users/:id into /users/{id}, wildcards become {wildcard}
+func openApiPath(path string) string {
+	path = openApiPathParam.ReplaceAllString(path, "{$1}")
+	var n1 int
+
+	for strings.ContainsAny(path, "*+") {
+		n1++
+		name := "{wildcard}"
+
+		if n1 > 1 {
+			name = fmt.Sprintf("{wildcard%d}", n1)
+		}
+
+		idx := strings.IndexAny(path, "*+")
+		path = path[:idx] + name + path[idx+1:]
+	}
+
+	return path
+}
+
+// mergeOpenApiPathParams adds the path params that the dto does not declare, all of them are required
+func mergeOpenApiPathParams(path string, params []*openApiParam) []*openApiParam {
+	names := make([]string, 0)
+
+	for _, groups := range openApiPathTemplate.FindAllStringSubmatch(openApiPath(path), -1) {
+		names = append(names, groups[1])
+	}
+
+	merged := make([]*openApiParam, 0, len(params)+len(names))
+
+	for _, name := range names {
+		var found *openApiParam
+
+		for _, p := range params {
+			if p.in == "path" && p.name == name {
+				found = p
+				break
+			}
+		}
+
+		if found == nil {
+			found = &openApiParam{name: name, in: "path", schema: map[string]interface{}{"type": "string"}}
+		}
+
+		found.required = true
+		merged = append(merged, found)
+	}
+
+	for _, p := range params {
+		if p.in == "path" {
+			continue
+		}
+
+		merged = append(merged, p)
+	}
+
+	return merged
+}
+
+func openApiOperationId(route *Route, used map[string]bool) string {
+	id := route.Name()
+
+	if id == "" {
+		id = strings.ToLower(route.Method()) + strings.ReplaceAll(openApiPath(route.Path()), "/", "_")
+	}
+
+	id = strings.Trim(openApiNameSanitizer.ReplaceAllString(id, "_"), "_")
+
+	if used[id] {
+		id += "_" + strings.ToLower(route.Method())
+	}
+
+	base := id
+
+	for n1 := 2; used[id]; n1++ {
+		id = fmt.Sprintf("%s_%d", base, n1)
+	}
+
+	used[id] = true
+	return id
+}
+
+func uniqueOpenApiCodes(codes []int) []int {
+	unique := make([]int, 0, len(codes))
+
+	for _, code := range codes {
+		if !openApiContainsCode(unique, code) {
+			unique = append(unique, code)
+		}
+	}
+
+	sort.Ints(unique)
+	return unique
+}
+
+func openApiContainsCode(codes []int, code int) bool {
+	for _, n1 := range codes {
+		if n1 == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func openApiContains(items []string, s1 string) bool {
+	for _, item := range items {
+		if item == s1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// openApiHasRateLimit skips the rate limit policy of a route profile without rate limit settings
+func openApiHasRateLimit(route *Route) bool {
+	for _, p := range route.Policies() {
+		if p.PolicyKind() != "RateLimit" {
+			continue
+		}
+
+		if rp, ok := p.(*routePolicy); ok && rp.argsFn != nil {
+			return len(rp.argsFn()) > 0
+		}
+
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,559 @@
+package mgboot
+
+import (
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+var openApiNameSanitizer = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// patterns of the validate rules that the schema vocabulary has no keyword for
+var openApiRulePatterns = map[string]string{
+	"mobile":  `^[1-9][0-9]{10,15}$`,
+	"idcard":  `^[1-9]\d{5}(18|19|20)\d{2}((0[1-9])|(10|11|12))(([0-2][1-9])|10|20|30|31)\d{3}[0-9Xx]$`,
+	"alpha":   `^[A-Za-z]+$`,
+	"numeric": `^[0-9]+$`,
+	"alnum":   `^[A-Za-z0-9]+$`,
+}
+
+type openApiSchemaBuilder struct {
+	schemas map[string]interface{}
+	names   map[reflect.Type]string
+}
+
+type openApiParam struct {
+	name     string
+	in       string
+	required bool
+	schema   map[string]interface{}
+}
+
+func newOpenApiSchemaBuilder() *openApiSchemaBuilder {
+	return &openApiSchemaBuilder{
+		schemas: map[string]interface{}{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+func (b *openApiSchemaBuilder) schemaOf(rt reflect.Type) map[string]interface{} {
+	for rt.Kind() == reflect.Ptr && rt != fileHeaderType {
+		rt = rt.Elem()
+	}
+
+	switch rt {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case fileHeaderType:
+		return map[string]interface{}{"type": "string", "format": "binary"}
+	case durationType:
+		return map[string]interface{}{"type": []string{"string", "integer"}, "examples": []interface{}{"1m30s"}}
+	}
+
+	switch rt.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32:
+		return map[string]interface{}{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case reflect.Slice, reflect.Array:
+		if rt.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+
+		return map[string]interface{}{"type": "array", "items": b.schemaOf(rt.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schemaOf(rt.Elem())}
+	case reflect.Struct:
+		if rt.Name() == "" {
+			return b.objectSchema(rt, false)
+		}
+
+		return b.refOf(rt)
+	}
+
+	return map[string]interface{}{}
+}
+
+// refOf registers a named struct under components.schemas, a name used by another package gets the package prefix
+func (b *openApiSchemaBuilder) refOf(rt reflect.Type) map[string]interface{} {
+	name, ok := b.names[rt]
+
+	if !ok {
+		name = openApiNameSanitizer.ReplaceAllString(rt.Name(), "_")
+
+		for other, otherName := range b.names {
+			if otherName == name && other != rt {
+				name = openApiNameSanitizer.ReplaceAllString(path.Base(rt.PkgPath())+"."+rt.Name(), "_")
+				break
+			}
+		}
+
+		b.names[rt] = name
+		b.schemas[name] = map[string]interface{}{}
+		b.schemas[name] = b.objectSchema(rt, false)
+	}
+
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+// objectSchema describes the fields that mgboot.Bind fills, skipParams leaves out the path, query and header fields
+func (b *openApiSchemaBuilder) objectSchema(rt reflect.Type, skipParams bool) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := make([]string, 0)
+	b.collectProperties(rt, skipParams, properties, &required)
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+func (b *openApiSchemaBuilder) collectProperties(rt reflect.Type, skipParams bool, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		if field.Tag.Get("json") == "-" || field.Tag.Get("bind") == "-" {
+			continue
+		}
+
+		if embedded := openApiEmbeddedStruct(field); embedded != nil {
+			b.collectProperties(embedded, skipParams, properties, required)
+			continue
+		}
+
+		if skipParams && openApiParamIn(field) != "" {
+			continue
+		}
+
+		name := candidateFieldNames(field, "form", "json", "xml")[0]
+		schema, isRequired := b.fieldSchema(field)
+		properties[name] = schema
+
+		if isRequired {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// params collects the path, query and header fields of a dto, body fields become query params when inBody is false
+func (b *openApiSchemaBuilder) params(rt reflect.Type, inBody bool) []*openApiParam {
+	params := make([]*openApiParam, 0)
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		if field.Tag.Get("json") == "-" || field.Tag.Get("bind") == "-" {
+			continue
+		}
+
+		if embedded := openApiEmbeddedStruct(field); embedded != nil {
+			params = append(params, b.params(embedded, inBody)...)
+			continue
+		}
+
+		in := openApiParamIn(field)
+
+		if in == "" {
+			if inBody {
+				continue
+			}
+
+			in = "query"
+		}
+
+		name := bindTagName(field)
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema, isRequired := b.fieldSchema(field)
+
+		params = append(params, &openApiParam{
+			name:     name,
+			in:       in,
+			required: isRequired || in == "path",
+			schema:   schema,
+		})
+	}
+
+	return params
+}
+
+// hasBodyFields reports whether a dto has fields read from the request body, and whether some of them are files
+func (b *openApiSchemaBuilder) hasBodyFields(rt reflect.Type) (hasFields, hasForm, hasFiles bool) {
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		if field.Tag.Get("json") == "-" || field.Tag.Get("bind") == "-" {
+			continue
+		}
+
+		if embedded := openApiEmbeddedStruct(field); embedded != nil {
+			f1, f2, f3 := b.hasBodyFields(embedded)
+			hasFields = hasFields || f1
+			hasForm = hasForm || f2
+			hasFiles = hasFiles || f3
+			continue
+		}
+
+		if openApiParamIn(field) != "" {
+			continue
+		}
+
+		hasFields = true
+
+		if tagName(field, "form") != "" {
+			hasForm = true
+		}
+
+		if isFileHeaderField(field.Type) {
+			hasFiles = true
+		}
+	}
+
+	return
+}
+
+func (b *openApiSchemaBuilder) fieldSchema(field reflect.StructField) (map[string]interface{}, bool) {
+	schema := b.schemaOf(field.Type)
+
+	if label := field.Tag.Get("label"); label != "" {
+		schema["description"] = label
+	}
+
+	if layout := field.Tag.Get("layout"); layout != "" && schema["format"] == "date-time" {
+		delete(schema, "format")
+		schema["examples"] = []interface{}{time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC).Format(layout)}
+	}
+
+	tag := field.Tag.Get("validate")
+
+	if tag == "" || tag == "-" {
+		return schema, false
+	}
+
+	rt := field.Type
+
+	for rt.Kind() == reflect.Ptr && rt != fileHeaderType {
+		rt = rt.Elem()
+	}
+
+	rules, _ := parseValidateRules(tag)
+	return schema, applyValidateRules(schema, rt, rules, tag)
+}
+
+func openApiEmbeddedStruct(field reflect.StructField) reflect.Type {
+	if !field.Anonymous || bindTagName(field) != "" {
+		return nil
+	}
+
+	rt := field.Type
+
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	if rt.Kind() != reflect.Struct || rt == timeType {
+		return nil
+	}
+
+	return rt
+}
+
+func openApiParamIn(field reflect.StructField) string {
+	for _, in := range []string{"path", "query", "header"} {
+		if tagName(field, in) != "" {
+			return in
+		}
+	}
+
+	return ""
+}
+
+// applyValidateRules maps the validate tag onto schema keywords and reports whether the field is required,
+// rules without a counterpart are kept in x-validate
+func applyValidateRules(schema map[string]interface{}, rt reflect.Type, rules []validateRule, tag string) bool {
+	var isRequired, unmapped bool
+
+	for idx, rule := range rules {
+		if rule.name == "dive" {
+			var itemType reflect.Type
+			var itemSchema map[string]interface{}
+
+			switch rt.Kind() {
+			case reflect.Slice, reflect.Array:
+				itemType = rt.Elem()
+				itemSchema, _ = schema["items"].(map[string]interface{})
+			case reflect.Map:
+				itemType = rt.Elem()
+				itemSchema, _ = schema["additionalProperties"].(map[string]interface{})
+			}
+
+			if itemSchema != nil {
+				for itemType.Kind() == reflect.Ptr && itemType != fileHeaderType {
+					itemType = itemType.Elem()
+				}
+
+				applyValidateRules(itemSchema, itemType, rules[idx+1:], tag)
+			}
+
+			break
+		}
+
+		if !applyValidateRule(schema, rt, rule) {
+			unmapped = true
+		}
+
+		if rule.name == "required" {
+			isRequired = true
+		}
+	}
+
+	if unmapped {
+		schema["x-validate"] = tag
+	}
+
+	return isRequired
+}
+
+func applyValidateRule(schema map[string]interface{}, rt reflect.Type, rule validateRule) bool {
+	var kind string
+
+	switch rt.Kind() {
+	case reflect.String:
+		kind = "string"
+	case reflect.Slice, reflect.Array:
+		kind = "array"
+	case reflect.Map:
+		kind = "object"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		kind = "number"
+	}
+
+	if rt == timeType || rt == durationType {
+		kind = ""
+	}
+
+	lengthKeywords := map[string][2]string{
+		"string": {"minLength", "maxLength"},
+		"array":  {"minItems", "maxItems"},
+		"object": {"minProperties", "maxProperties"},
+	}
+
+	switch rule.name {
+	case "omitempty", "required", "required_with", "required_without":
+		return true
+	case "min", "gte", "max", "lte", "gt", "lt", "len":
+		n1, err := strconv.ParseFloat(rule.param, 64)
+
+		if err != nil || kind == "" {
+			return false
+		}
+
+		if kind == "number" {
+			switch rule.name {
+			case "min", "gte":
+				schema["minimum"] = openApiNumber(n1)
+			case "max", "lte":
+				schema["maximum"] = openApiNumber(n1)
+			case "gt":
+				schema["exclusiveMinimum"] = openApiNumber(n1)
+			case "lt":
+				schema["exclusiveMaximum"] = openApiNumber(n1)
+			default:
+				schema["const"] = openApiNumber(n1)
+			}
+
+			return true
+		}
+
+		keywords := lengthKeywords[kind]
+		n2 := int(n1)
+
+		switch rule.name {
+		case "min", "gte":
+			schema[keywords[0]] = n2
+		case "max", "lte":
+			schema[keywords[1]] = n2
+		case "gt":
+			schema[keywords[0]] = n2 + 1
+		case "lt":
+			schema[keywords[1]] = n2 - 1
+		default:
+			schema[keywords[0]] = n2
+			schema[keywords[1]] = n2
+		}
+
+		return true
+	case "eq", "ne":
+		var value interface{} = rule.param
+
+		if kind == "number" {
+			n1, err := strconv.ParseFloat(rule.param, 64)
+
+			if err != nil {
+				return false
+			}
+
+			value = openApiNumber(n1)
+		} else if kind != "string" {
+			return false
+		}
+
+		if rule.name == "eq" {
+			schema["const"] = value
+		} else {
+			schema["not"] = map[string]interface{}{"const": value}
+		}
+
+		return true
+	case "oneof":
+		values := make([]interface{}, 0)
+
+		for _, s1 := range strings.Fields(rule.param) {
+			if kind == "number" {
+				if n1, err := strconv.ParseFloat(s1, 64); err == nil {
+					values = append(values, openApiNumber(n1))
+				}
+
+				continue
+			}
+
+			values = append(values, s1)
+		}
+
+		schema["enum"] = values
+		return true
+	case "email":
+		schema["format"] = "email"
+		return true
+	case "url":
+		schema["format"] = "uri"
+		return true
+	case "ip":
+		schema["anyOf"] = []interface{}{
+			map[string]interface{}{"format": "ipv4"},
+			map[string]interface{}{"format": "ipv6"},
+		}
+
+		return true
+	case "regexp":
+		schema["pattern"] = rule.param
+		return true
+	}
+
+	if pattern, ok := openApiRulePatterns[rule.name]; ok {
+		schema["pattern"] = pattern
+		return true
+	}
+
+	return false
+}
+
+// validatePolicySchema describes the fields checked by a Validate policy, rules look like name@Mobile@CheckOnNotEmpty
+func validatePolicySchema(rules []string) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := make([]string, 0)
+
+	for _, rule := range rules {
+		optional := strings.Contains(rule, "@CheckOnNotEmpty") || strings.Contains(rule, "@WithNotEmpty")
+		rule = strings.ReplaceAll(rule, "@CheckOnNotEmpty", "")
+		rule = strings.ReplaceAll(rule, "@WithNotEmpty", "")
+		var description string
+
+		if idx := strings.Index(rule, "@msg:"); idx >= 0 {
+			description = strings.TrimSpace(rule[idx+5:])
+			rule = rule[:idx]
+		}
+
+		name := strings.TrimSpace(rule)
+		fn := "Required"
+
+		if idx := strings.Index(rule, "@"); idx >= 0 {
+			name = strings.TrimSpace(rule[:idx])
+			fn = strings.TrimSpace(rule[idx+1:])
+		}
+
+		if name == "" {
+			continue
+		}
+
+		schema, ok := properties[name].(map[string]interface{})
+
+		if !ok {
+			schema = map[string]interface{}{"type": "string"}
+			properties[name] = schema
+
+			if !optional {
+				required = append(required, name)
+			}
+		}
+
+		if description != "" {
+			schema["description"] = description
+		}
+
+		fnName := fn
+
+		if idx := strings.Index(fn, ":"); idx >= 0 {
+			fnName = strings.TrimSpace(fn[:idx])
+		}
+
+		switch fnName {
+		case "Required":
+		case "Email":
+			schema["format"] = "email"
+		case "Mobile", "Idcard":
+			schema["pattern"] = openApiRulePatterns[strings.ToLower(fnName)]
+		default:
+			schema["x-validate"] = fn
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+func openApiNumber(n1 float64) interface{} {
+	if n1 == float64(int64(n1)) {
+		return int64(n1)
+	}
+
+	return n1
+}
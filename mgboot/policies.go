@@ -0,0 +1,189 @@
+package mgboot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"strings"
+	"time"
+)
+
+// Profile binds the routes to a RouteProfile, which adds its cors, rate limit and jwt auth policies,
+// selects the jwt settings used by GetJwt and JwtClaim* and applies its error envelope, the requests
+// are rejected when the profile or its jwt settings are missing, a public group adds PermitAll next to Profile
+func Profile(name string) RoutePolicy {
+	return &routePolicy{
+		kind:  "Profile",
+		name:  "Profile(" + name + ")",
+		order: PolicyOrderProfile,
+		args:  []string{name},
+		handler: func(_ *Route) fiber.Handler {
+			return func(ctx *fiber.Ctx) error {
+				ctx.Locals("RouteProfile", name)
+				return ctx.Next()
+			}
+		},
+	}
+}
+
+func Cors(settings ...*CorsSettings) RoutePolicy {
+	var st *CorsSettings
+
+	if len(settings) > 0 {
+		st = settings[0]
+	}
+
+	return &routePolicy{
+		kind:  "Cors",
+		name:  "Cors",
+		order: PolicyOrderCors,
+		handler: func(_ *Route) fiber.Handler {
+			return func(ctx *fiber.Ctx) error {
+				if ApplyCors(ctx, st) {
+					return nil
+				}
+
+				return ctx.Next()
+			}
+		},
+	}
+}
+
+func RateLimit(total int, duration time.Duration, limitByIp ...bool) RoutePolicy {
+	byIp := len(limitByIp) > 0 && limitByIp[0]
+	name := fmt.Sprintf("RateLimit(%d/%s)", total, duration)
+
+	if byIp {
+		name = fmt.Sprintf("RateLimit(%d/%s, ip)", total, duration)
+	}
+
+	settings := map[string]interface{}{
+		"total":     total,
+		"duration":  duration,
+		"limitByIp": byIp,
+	}
+
+	return &routePolicy{
+		kind:  "RateLimit",
+		name:  name,
+		order: PolicyOrderRateLimit,
+		handler: func(route *Route) fiber.Handler {
+			return func(ctx *fiber.Ctx) error {
+				if err := RateLimitCheck(ctx, route.Id(), settings); err != nil {
+					return SendOutput(ctx, nil, err)
+				}
+
+				return ctx.Next()
+			}
+		},
+	}
+}
+
+func JwtAuth(settingsKey string) RoutePolicy {
+	return &routePolicy{
+		kind:  "JwtAuth",
+		name:  "JwtAuth(" + settingsKey + ")",
+		order: PolicyOrderJwtAuth,
+		args:  []string{settingsKey},
+		handler: func(_ *Route) fiber.Handler {
+			return func(ctx *fiber.Ctx) error {
+				if err := JwtAuthCheck(ctx, settingsKey); err != nil {
+					return SendOutput(ctx, nil, err)
+				}
+
+				return ctx.Next()
+			}
+		},
+	}
+}
+
+// PermitAll replaces the JwtAuth policy of the group for a route which does not require a token
+func PermitAll() RoutePolicy {
+	return &routePolicy{
+		kind:  "JwtAuth",
+		name:  "PermitAll",
+		order: PolicyOrderJwtAuth,
+		handler: func(_ *Route) fiber.Handler {
+			return func(ctx *fiber.Ctx) error {
+				return ctx.Next()
+			}
+		},
+	}
+}
+
+func Validate(rules ...string) RoutePolicy {
+	return newValidatePolicy(rules, false)
+}
+
+// ValidateFailfast stops at the first failed rule and reports only that one
+func ValidateFailfast(rules ...string) RoutePolicy {
+	return newValidatePolicy(rules, true)
+}
+
+func Timeout(timeout time.Duration) RoutePolicy {
+	return &routePolicy{
+		kind:  "Timeout",
+		name:  "Timeout(" + timeout.String() + ")",
+		order: PolicyOrderTimeout,
+		handler: func(_ *Route) fiber.Handler {
+			return func(ctx *fiber.Ctx) error {
+				c, cancel := context.WithTimeout(ctx.UserContext(), timeout)
+				defer cancel()
+				ctx.SetUserContext(c)
+				err := ctx.Next()
+
+				// handlers observe the deadline through ctx.UserContext(), a late response is replaced
+				if errors.Is(err, context.DeadlineExceeded) || c.Err() == context.DeadlineExceeded {
+					ctx.Response().ResetBody()
+					return SendOutput(ctx, nil, NewRequestTimeoutError(timeout))
+				}
+
+				return err
+			}
+		},
+	}
+}
+
+func newValidatePolicy(rules []string, failfast bool) RoutePolicy {
+	settings := make([]string, 0, len(rules)+1)
+	settings = append(settings, rules...)
+	name := fmt.Sprintf("Validate(%s)", strings.Join(ruleFieldNames(rules), ", "))
+
+	if failfast {
+		settings = append(settings, "true")
+		name = fmt.Sprintf("ValidateFailfast(%s)", strings.Join(ruleFieldNames(rules), ", "))
+	}
+
+	return &routePolicy{
+		kind:  "Validate",
+		name:  name,
+		order: PolicyOrderValidate,
+		args:  rules,
+		handler: func(_ *Route) fiber.Handler {
+			return func(ctx *fiber.Ctx) error {
+				if err := ValidateCheck(ctx, settings); err != nil {
+					return SendOutput(ctx, nil, err)
+				}
+
+				return ctx.Next()
+			}
+		},
+	}
+}
+
+func ruleFieldNames(rules []string) []string {
+	names := make([]string, 0, len(rules))
+
+	for _, rule := range rules {
+		name := strings.TrimSpace(rule)
+
+		if idx := strings.Index(name, "@"); idx > 0 {
+			name = name[:idx]
+		}
+
+		names = append(names, name)
+	}
+
+	return names
+}
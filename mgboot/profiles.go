@@ -0,0 +1,252 @@
+package mgboot
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"strings"
+	"sync"
+)
+
+var routeProfiles = map[string]*RouteProfile{}
+var routeProfilesMu = &sync.RWMutex{}
+
+func WithRouteProfile(name string, settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("routeProfiles." + name)
+	}
+
+	WithRouteProfiles(NewRouteProfile(name, _settings))
+}
+
+func WithRouteProfiles(profiles ...*RouteProfile) {
+	routeProfilesMu.Lock()
+	defer routeProfilesMu.Unlock()
+
+	for _, p := range profiles {
+		if p != nil {
+			routeProfiles[p.Name()] = p
+		}
+	}
+}
+
+func GetRouteProfile(name string) *RouteProfile {
+	routeProfilesMu.RLock()
+	defer routeProfilesMu.RUnlock()
+	return routeProfiles[name]
+}
+
+// GetCurrentRouteProfile returns the profile of the matched route, nil when the route has no Profile policy
+func GetCurrentRouteProfile(ctx *fiber.Ctx) *RouteProfile {
+	name, ok := ctx.Locals("RouteProfile").(string)
+
+	if !ok || name == "" {
+		return nil
+	}
+
+	return GetRouteProfile(name)
+}
+
+// NewErrorEnvelope renames the fields of the json error payloads, supported keys: code, msg, data
+// (the new names, empty to keep, "-" to drop the field) and extra (fields added to every payload)
+func NewErrorEnvelope(settings map[string]interface{}) ErrorEnvelope {
+	names := map[string]string{}
+
+	for _, key := range []string{"code", "msg", "data"} {
+		if s1 := castx.ToString(settings[key]); s1 != "" {
+			names[key] = s1
+		}
+	}
+
+	extra := castx.ToStringMap(settings["extra"])
+
+	return func(_ *fiber.Ctx, _ error, payload ResponsePayload) ResponsePayload {
+		pl, ok := payload.(JsonResponse)
+
+		if !ok {
+			return payload
+		}
+
+		map1, ok := pl.payload.(map[string]interface{})
+
+		if !ok {
+			return payload
+		}
+
+		map2 := make(map[string]interface{}, len(map1)+len(extra))
+
+		for key, value := range extra {
+			map2[key] = value
+		}
+
+		for key, value := range map1 {
+			name, ok := names[key]
+
+			if !ok {
+				map2[key] = value
+				continue
+			}
+
+			if name != "-" {
+				map2[name] = value
+			}
+		}
+
+		return NewJsonResponse(map2)
+	}
+}
+
+// expandProfilePolicies adds the policies of the profile next to each Profile policy, an explicit policy
+// of the same kind in policies is kept instead, the profile is resolved on every request so that
+// it may be registered after the routes
+func expandProfilePolicies(policies []RoutePolicy) []RoutePolicy {
+	var profileName string
+	kinds := map[string]bool{}
+
+	for _, p := range policies {
+		kinds[p.PolicyKind()] = true
+
+		if pp, ok := p.(*routePolicy); ok && pp.kind == "Profile" {
+			profileName = pp.args[0]
+		}
+	}
+
+	if profileName == "" {
+		return policies
+	}
+
+	expanded := make([]RoutePolicy, 0, len(policies)+3)
+	expanded = append(expanded, policies...)
+
+	for _, p := range profilePolicies(profileName) {
+		if !kinds[p.PolicyKind()] {
+			expanded = append(expanded, p)
+		}
+	}
+
+	return expanded
+}
+
+// ValidateRouteProfiles reports the routes whose profile jwt auth can not be checked, either the profile
+// is not registered or its jwt settings are missing, a public route of a profile uses PermitAll
+func ValidateRouteProfiles(router *Router) error {
+	messages := make([]string, 0)
+
+	for _, route := range router.Routes() {
+		for _, p := range route.Policies() {
+			rp, ok := p.(*routePolicy)
+
+			if !ok || rp.kind != "JwtAuth" || rp.profile == "" {
+				continue
+			}
+
+			if err := checkProfileJwtSettings(rp.profile); err != nil {
+				messages = append(messages, fmt.Sprintf("%s %s: %v", route.Method(), route.Path(), err))
+			}
+		}
+	}
+
+	if len(messages) > 0 {
+		return errors.New("in mgboot.ValidateRouteProfiles, " + strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+func checkProfileJwtSettings(name string) error {
+	p := GetRouteProfile(name)
+
+	if p == nil {
+		return fmt.Errorf("route profile %s not found", name)
+	}
+
+	if p.JwtKey() == "" {
+		return fmt.Errorf("route profile %s has no jwt settings key", name)
+	}
+
+	if GetJwtSettings(p.JwtKey()) == nil {
+		return fmt.Errorf("jwt settings %s of route profile %s not found", p.JwtKey(), name)
+	}
+
+	return nil
+}
+
+func profilePolicies(name string) []RoutePolicy {
+	cors := &routePolicy{
+		kind:  "Cors",
+		name:  "Cors(@" + name + ")",
+		order: PolicyOrderCors,
+		handler: func(_ *Route) fiber.Handler {
+			return func(ctx *fiber.Ctx) error {
+				if p := GetRouteProfile(name); p != nil && p.CorsEnabled() && ApplyCors(ctx, p.Cors()) {
+					return nil
+				}
+
+				return ctx.Next()
+			}
+		},
+	}
+
+	rateLimit := &routePolicy{
+		kind:  "RateLimit",
+		name:  "RateLimit(@" + name + ")",
+		order: PolicyOrderRateLimit,
+		argsFn: func() []string {
+			if p := GetRouteProfile(name); p != nil && p.rateLimit != nil {
+				total, duration, _ := p.RateLimit()
+				return []string{fmt.Sprintf("%d/%s", total, duration)}
+			}
+
+			return nil
+		},
+		handler: func(route *Route) fiber.Handler {
+			return func(ctx *fiber.Ctx) error {
+				if p := GetRouteProfile(name); p != nil && p.rateLimit != nil {
+					if err := RateLimitCheck(ctx, route.Id(), p.rateLimit); err != nil {
+						return SendOutput(ctx, nil, err)
+					}
+				}
+
+				return ctx.Next()
+			}
+		},
+	}
+
+	jwtAuth := &routePolicy{
+		kind:    "JwtAuth",
+		name:    "JwtAuth(@" + name + ")",
+		order:   PolicyOrderJwtAuth,
+		profile: name,
+		argsFn: func() []string {
+			if p := GetRouteProfile(name); p != nil && p.JwtKey() != "" {
+				return []string{p.JwtKey()}
+			}
+
+			return nil
+		},
+		handler: func(_ *Route) fiber.Handler {
+			return func(ctx *fiber.Ctx) error {
+				// a misconfigured profile must not leave the routes open
+				if err := checkProfileJwtSettings(name); err != nil {
+					return SendOutput(ctx, nil, err)
+				}
+
+				if err := JwtAuthCheck(ctx, GetRouteProfile(name).JwtKey()); err != nil {
+					return SendOutput(ctx, nil, err)
+				}
+
+				return ctx.Next()
+			}
+		},
+	}
+
+	return []RoutePolicy{cors, rateLimit, jwtAuth}
+}
@@ -0,0 +1,46 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/stringx"
+	"github.com/meiguonet/mgboot-go-fiber/enum/ReqParamSecurityMode"
+)
+
+var htmlPurifier *HtmlPurifier
+
+func WithHtmlPurifierSettings(settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("htmlPurifier")
+	}
+
+	htmlPurifier = NewHtmlPurifier(_settings)
+}
+
+func GetHtmlPurifier() *HtmlPurifier {
+	if htmlPurifier == nil {
+		htmlPurifier = NewHtmlPurifier(map[string]interface{}{})
+	}
+
+	return htmlPurifier
+}
+
+func PurifyHtml(contents string) string {
+	return GetHtmlPurifier().Purify(contents)
+}
+
+func applySecurityMode(value string, mode int) string {
+	switch mode {
+	case ReqParamSecurityMode.None:
+		return value
+	case ReqParamSecurityMode.HtmlPurify:
+		return PurifyHtml(value)
+	default:
+		return stringx.StripTags(value)
+	}
+}
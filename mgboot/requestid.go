@@ -0,0 +1,88 @@
+package mgboot
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"regexp"
+)
+
+var requestIdHeader = "X-Request-Id"
+var requestIdRegexp = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,128}$`)
+
+func RequestIdHeader(headerName ...string) string {
+	if len(headerName) > 0 && headerName[0] != "" {
+		requestIdHeader = headerName[0]
+	}
+
+	return requestIdHeader
+}
+
+func GetRequestId(ctx *fiber.Ctx) string {
+	if tc := GetTraceContext(ctx); tc != nil {
+		return tc.RequestId()
+	}
+
+	return ""
+}
+
+func GetTraceContext(ctx *fiber.Ctx) *TraceContext {
+	if ctx == nil {
+		return nil
+	}
+
+	if tc, ok := ctx.Locals("TraceContext").(*TraceContext); ok {
+		return tc
+	}
+
+	return nil
+}
+
+// TraceContextFromMap restores the trace context carried by a task payload or similar map,
+// the returned context is a child span of the publisher.
+func TraceContextFromMap(map1 map[string]interface{}) *TraceContext {
+	requestId, _ := map1["requestId"].(string)
+	traceparent, _ := map1["traceparent"].(string)
+	parent := ParseTraceparent(traceparent)
+
+	if parent == nil {
+		if requestId == "" {
+			return nil
+		}
+
+		return NewTraceContext(requestId)
+	}
+
+	parent.requestId = requestId
+
+	if parent.requestId == "" {
+		parent.requestId = parent.traceId
+	}
+
+	parent.traceState, _ = map1["tracestate"].(string)
+	return parent.Child()
+}
+
+func resolveTraceContext(ctx *fiber.Ctx) *TraceContext {
+	requestId := ctx.Get(requestIdHeader)
+
+	if !requestIdRegexp.MatchString(requestId) {
+		requestId = ""
+	}
+
+	parent := ParseTraceparent(ctx.Get("traceparent"))
+
+	if parent == nil {
+		return NewTraceContext(requestId)
+	}
+
+	parent.requestId = requestId
+
+	if parent.requestId == "" {
+		parent.requestId = parent.traceId
+	}
+
+	if s1 := ctx.Get("tracestate"); len(s1) <= 512 {
+		parent.traceState = s1
+	}
+
+	return parent.Child()
+}
@@ -0,0 +1,104 @@
+package mgboot
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"strings"
+	"time"
+)
+
+// RecordTiming adds d to the named timing of the current request, timings are reported by the slow request log
+func RecordTiming(ctx *fiber.Ctx, name string, d time.Duration) {
+	timings, ok := ctx.Locals("Timings").(map[string]time.Duration)
+
+	if !ok {
+		timings = map[string]time.Duration{}
+		ctx.Locals("Timings", timings)
+	}
+
+	timings[name] += d
+}
+
+// StartTiming returns a func that records the time elapsed since the call, use it as: defer mgboot.StartTiming(ctx, "db")()
+func StartTiming(ctx *fiber.Ctx, name string) func() {
+	start := time.Now()
+
+	return func() {
+		RecordTiming(ctx, name, time.Since(start))
+	}
+}
+
+func GetTimings(ctx *fiber.Ctx) map[string]time.Duration {
+	if timings, ok := ctx.Locals("Timings").(map[string]time.Duration); ok {
+		return timings
+	}
+
+	return map[string]time.Duration{}
+}
+
+func LogSlowRequest(ctx *fiber.Ctx, start time.Time, err error) {
+	threshold := SlowRequestThreshold()
+
+	if threshold <= 0 || !ExecuteTimeLogEnabled() {
+		return
+	}
+
+	elapsed := time.Since(start)
+
+	if elapsed < threshold {
+		return
+	}
+
+	statusCode := resolveStatusCode(ctx, err)
+	timings := map[string]string{}
+
+	for name, d := range GetTimings(ctx) {
+		timings[name] = d.String()
+	}
+
+	timings["total"] = elapsed.String()
+
+	details := map[string]interface{}{
+		"method":          ctx.Method(),
+		"uri":             maskRequestUri(ctx),
+		"route":           resolveRoutePath(ctx, statusCode),
+		"status":          statusCode,
+		"clientIp":        GetClientIp(ctx),
+		"threshold":       threshold.String(),
+		"timings":         timings,
+		"requestHeaders":  MaskRequestHeaders(ctx),
+		"requestBody":     MaskRequestBody(ctx),
+		"responseHeaders": MaskResponseHeaders(ctx),
+		"responseBody":    MaskResponseBody(ctx),
+	}
+
+	if err != nil {
+		details["error"] = err.Error()
+	}
+
+	NewContextLogger(ExecuteTimeLogLogger(), ctx).Warn("slow request: " + strings.TrimSpace(jsonx.ToJson(details)))
+}
+
+func isLoggableContentType(contentType string) bool {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+
+	if strings.Contains(contentType, ";") {
+		contentType = strings.TrimSpace(contentType[:strings.Index(contentType, ";")])
+	}
+
+	if contentType == "" {
+		return false
+	}
+
+	for _, s1 := range ResponseLogContentTypes() {
+		if s1 == contentType || s1 == "*" {
+			return true
+		}
+
+		if strings.HasSuffix(s1, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(s1, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -31,6 +31,8 @@ func WithCorsSettings(settings ...map[string]interface{}) {
 	if len(_settings) < 1 {
 		_settings = AppConf.GetMap("cors")
 	}
+
+	corsSettings = NewCorsSettings(_settings)
 }
 
 func GetCorsSettings() *CorsSettings {
@@ -85,6 +87,7 @@ func WithJwtSettings(key string, settings ...map[string]interface{}) {
 	} else {
 		jwtSettings[key] = NewJwtSettings(_settings)
 	}
+	registerBuiltinJwtSettings(key)
 }
 
 func GetJwtSettings(key string) *JwtSettings {
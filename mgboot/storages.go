@@ -0,0 +1,184 @@
+package mgboot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/stringx"
+	"io"
+	"io/ioutil"
+	"mime"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+var storages = map[string]Storage{}
+var storagesMu = &sync.RWMutex{}
+
+func NewStorage(settings map[string]interface{}) Storage {
+	switch strings.ToLower(castx.ToString(settings["driver"])) {
+	case "s3":
+		return NewS3Storage(settings)
+	default:
+		return NewLocalStorage(settings)
+	}
+}
+
+func WithStorage(name string, storage Storage) {
+	storagesMu.Lock()
+	storages[name] = storage
+	storagesMu.Unlock()
+}
+
+func WithStorageSettings(settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("storage")
+	}
+
+	for name, value := range _settings {
+		WithStorage(name, NewStorage(castx.ToStringMap(value)))
+	}
+}
+
+func GetStorage(name ...string) Storage {
+	_name := "default"
+
+	if len(name) > 0 && name[0] != "" {
+		_name = name[0]
+	}
+
+	storagesMu.RLock()
+	defer storagesMu.RUnlock()
+	return storages[_name]
+}
+
+// @param opts map[string]interface{}, supported keys:
+// dir: key prefix, imageProcess: options of NewImageProcessOptions, plus all options of ValidateUploadedFile
+func SaveUploadedFile(ctx *fiber.Ctx, field string, storage Storage, opts ...map[string]interface{}) (*StorageObject, error) {
+	if storage == nil {
+		return nil, errors.New("in mgboot.SaveUploadedFile function, storage not set")
+	}
+
+	_opts := map[string]interface{}{}
+
+	for key, value := range firstOptions(opts) {
+		_opts[key] = value
+	}
+
+	if _, ok := _opts["field"]; !ok {
+		_opts["field"] = field
+	}
+
+	fh := GetUploadedFile(ctx, field)
+
+	if err := ValidateUploadedFile(fh, _opts); err != nil {
+		return nil, err
+	}
+
+	f, err := fh.Open()
+
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := ioutil.ReadAll(f)
+	f.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := fh.Filename
+	contentType := mime.TypeByExtension(path.Ext(fileName))
+
+	if contentType == "" {
+		contentType = fh.Header.Get(fiber.HeaderContentType)
+	}
+
+	if map1, ok := _opts["imageProcess"].(map[string]interface{}); ok && len(map1) > 0 {
+		if buf, contentType, err = ProcessImage(buf, NewImageProcessOptions(map1)); err != nil {
+			return nil, err
+		}
+
+		fileName = strings.TrimSuffix(fileName, path.Ext(fileName)) + "." + strings.TrimPrefix(contentType, "image/")
+	}
+
+	sum := sha256.Sum256(buf)
+	key := contentAddressedKey(castx.ToString(_opts["dir"]), hex.EncodeToString(sum[:]), fileName)
+
+	if obj, err := storage.Stat(key); err == nil {
+		return obj, nil
+	}
+
+	if err = storage.Put(key, bytes.NewReader(buf), map[string]interface{}{"contentType": contentType, "size": int64(len(buf))}); err != nil {
+		return nil, err
+	}
+
+	return NewStorageObject(key, int64(len(buf)), contentType, "", time.Now()), nil
+}
+
+type storageUploadSink struct {
+	storage Storage
+	dir     string
+}
+
+func NewStorageUploadSink(storage Storage, dir ...string) *storageUploadSink {
+	var _dir string
+
+	if len(dir) > 0 {
+		_dir = dir[0]
+	}
+
+	return &storageUploadSink{storage: storage, dir: _dir}
+}
+
+func (s *storageUploadSink) Save(_, fileName string, r io.Reader) (string, error) {
+	key := contentAddressedKey(s.dir, strings.ToLower(stringx.GetRandomString(32)), fileName)
+
+	if err := s.storage.Put(key, r, map[string]interface{}{"contentType": mime.TypeByExtension(path.Ext(key))}); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+func (s *storageUploadSink) Remove(location string) error {
+	return s.storage.Delete(location)
+}
+
+func contentAddressedKey(dir, hash, fileName string) string {
+	ext := strings.ToLower(path.Ext(fileName))
+
+	if len(ext) > 11 || strings.ContainsAny(ext, "/\\ ") {
+		ext = ""
+	}
+
+	key := hash[:2] + "/" + hash[2:4] + "/" + hash + ext
+	dir = strings.Trim(dir, "/")
+
+	if dir != "" {
+		key = dir + "/" + key
+	}
+
+	return key
+}
+
+func firstOptions(opts []map[string]interface{}) map[string]interface{} {
+	if len(opts) > 0 && opts[0] != nil {
+		return opts[0]
+	}
+
+	return map[string]interface{}{}
+}
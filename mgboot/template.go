@@ -0,0 +1,296 @@
+package mgboot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/fsx"
+	"github.com/meiguonet/mgboot-go-common/util/stringx"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+var templateFS fs.FS
+var templateExt = ".html"
+var templateLayoutsDir = "layouts"
+var templatePartialsDir = "partials"
+var templateDefaultLayout string
+var templateReload bool
+var templateFuncs = template.FuncMap{}
+var templateCache = map[string]*template.Template{}
+var templateMu = &sync.RWMutex{}
+
+// WithTemplateSettings supported keys: dir, extension, layoutsDir, partialsDir, layout, reload,
+// the templates are precompiled when reload is off and the parse error is returned
+func WithTemplateSettings(settings ...map[string]interface{}) error {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("template")
+	}
+
+	if s1 := castx.ToString(_settings["dir"]); s1 != "" {
+		WithTemplateDir(s1)
+	}
+
+	if s1 := castx.ToString(_settings["extension"]); s1 != "" {
+		templateExt = stringx.EnsureLeft(s1, ".")
+	}
+
+	if s1 := castx.ToString(_settings["layoutsDir"]); s1 != "" {
+		templateLayoutsDir = strings.Trim(s1, "/")
+	}
+
+	if s1 := castx.ToString(_settings["partialsDir"]); s1 != "" {
+		templatePartialsDir = strings.Trim(s1, "/")
+	}
+
+	if s1 := castx.ToString(_settings["layout"]); s1 != "" {
+		templateDefaultLayout = s1
+	}
+
+	reload := AppConf.GetEnv() == "dev"
+
+	if b1, err := castx.ToBoolE(_settings["reload"]); err == nil {
+		reload = b1
+	}
+
+	if TemplateReload(reload) {
+		return nil
+	}
+
+	templateMu.RLock()
+	fsys := templateFS
+	templateMu.RUnlock()
+
+	if fsys == nil {
+		return nil
+	}
+
+	return PrecompileTemplates()
+}
+
+func WithTemplateDir(dir string) {
+	dir = fsx.GetRealpath(dir)
+
+	if stat, err := os.Stat(dir); err == nil && stat.IsDir() {
+		WithTemplateFS(os.DirFS(dir))
+	}
+}
+
+func WithTemplateFS(fsys fs.FS) {
+	templateMu.Lock()
+	templateFS = fsys
+	templateCache = map[string]*template.Template{}
+	templateMu.Unlock()
+}
+
+func WithTemplateFunc(name string, fn interface{}) {
+	templateMu.Lock()
+	templateFuncs[name] = fn
+	templateMu.Unlock()
+	clearTemplateCache()
+}
+
+func WithTemplateFuncs(funcs template.FuncMap) {
+	templateMu.Lock()
+
+	for name, fn := range funcs {
+		templateFuncs[name] = fn
+	}
+
+	templateMu.Unlock()
+	clearTemplateCache()
+}
+
+func TemplateReload(flag ...bool) bool {
+	templateMu.Lock()
+	defer templateMu.Unlock()
+
+	if len(flag) > 0 {
+		templateReload = flag[0]
+		templateCache = map[string]*template.Template{}
+	}
+
+	return templateReload
+}
+
+// PrecompileTemplates parses the layouts and every page with the default layout, the pages are cached,
+// so that a syntax error of a page, a layout or a partial is reported at boot instead of on the first request
+func PrecompileTemplates() error {
+	templateMu.RLock()
+	fsys := templateFS
+	templateMu.RUnlock()
+
+	if fsys == nil {
+		return errors.New("in mgboot.PrecompileTemplates function, template dir or fs not set")
+	}
+
+	layouts := make([]string, 0)
+	pages := make([]string, 0)
+
+	err := fs.WalkDir(fsys, ".", func(fpath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(fpath, templateExt) {
+			return err
+		}
+
+		switch {
+		case strings.HasPrefix(fpath, templateLayoutsDir+"/"):
+			layouts = append(layouts, fpath)
+		case strings.HasPrefix(fpath, templatePartialsDir+"/"):
+		default:
+			pages = append(pages, strings.TrimSuffix(fpath, templateExt))
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("in mgboot.PrecompileTemplates function, %v", err)
+	}
+
+	for _, fpath := range layouts {
+		if _, err := parseTemplateFiles(fsys, fpath, []string{fpath}); err != nil {
+			return fmt.Errorf("in mgboot.PrecompileTemplates function, %v", err)
+		}
+	}
+
+	for _, name := range pages {
+		if _, err := loadTemplate(name, templateDefaultLayout); err != nil {
+			return fmt.Errorf("in mgboot.PrecompileTemplates function, %v", err)
+		}
+	}
+
+	return nil
+}
+
+func RenderTemplate(name string, data interface{}, layout ...string) (string, error) {
+	_layout := templateDefaultLayout
+
+	if len(layout) > 0 {
+		_layout = layout[0]
+	}
+
+	tpl, err := loadTemplate(name, _layout)
+
+	if err != nil {
+		return "", err
+	}
+
+	buf := &bytes.Buffer{}
+	entry := path.Base(templateFileName(name))
+
+	if _layout != "" {
+		entry = path.Base(templateFileName(path.Join(templateLayoutsDir, _layout)))
+	}
+
+	if err := tpl.ExecuteTemplate(buf, entry, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func loadTemplate(name, layout string) (*template.Template, error) {
+	cacheKey := name + "@" + layout
+	templateMu.RLock()
+	fsys, reload := templateFS, templateReload
+	tpl, ok := templateCache[cacheKey]
+	templateMu.RUnlock()
+
+	if fsys == nil {
+		return nil, errors.New("in mgboot.RenderTemplate function, template dir or fs not set")
+	}
+
+	if ok && !reload {
+		return tpl, nil
+	}
+
+	files := make([]string, 0)
+
+	if layout != "" {
+		files = append(files, templateFileName(path.Join(templateLayoutsDir, layout)))
+	}
+
+	files = append(files, templateFileName(name))
+	tpl, err := parseTemplateFiles(fsys, name, files)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !reload {
+		templateMu.Lock()
+		templateCache[cacheKey] = tpl
+		templateMu.Unlock()
+	}
+
+	return tpl, nil
+}
+
+// parseTemplateFiles parses files with the partials, which are put before the last file so that it may
+// override their blocks
+func parseTemplateFiles(fsys fs.FS, name string, files []string) (*template.Template, error) {
+	last := len(files) - 1
+
+	if partials, err := fs.Glob(fsys, path.Join(templatePartialsDir, "*"+templateExt)); err == nil {
+		files = append(append(files[:last:last], partials...), files[last])
+	}
+
+	templateMu.RLock()
+	tpl := template.New(path.Base(files[0])).Funcs(builtinTemplateFuncs()).Funcs(templateFuncs)
+	templateMu.RUnlock()
+	tpl, err := tpl.ParseFS(fsys, files...)
+
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse template %s: %w", name, err)
+	}
+
+	return tpl, nil
+}
+
+func templateFileName(name string) string {
+	name = strings.TrimLeft(strings.ReplaceAll(name, "\\", "/"), "/")
+
+	if strings.HasSuffix(name, templateExt) {
+		return name
+	}
+
+	return name + templateExt
+}
+
+func clearTemplateCache() {
+	templateMu.Lock()
+	templateCache = map[string]*template.Template{}
+	templateMu.Unlock()
+}
+
+func builtinTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"raw": func(s string) template.HTML {
+			return template.HTML(s)
+		},
+		"dict": func(args ...interface{}) map[string]interface{} {
+			map1 := map[string]interface{}{}
+
+			for i := 0; i+1 < len(args); i += 2 {
+				map1[castx.ToString(args[i])] = args[i+1]
+			}
+
+			return map1
+		},
+		"appConf": func(key string) string {
+			return AppConf.GetString(key)
+		},
+	}
+}
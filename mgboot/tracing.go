@@ -0,0 +1,216 @@
+package mgboot
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-fiber/cachex"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+var tracerProvider *sdktrace.TracerProvider
+
+// WithTracingSettings builds the tracer provider from settings, supported keys:
+// enabled, serviceName, exporter (stdout|otlp), endpoint, urlPath, insecure, headers, sampleRatio
+func WithTracingSettings(settings ...map[string]interface{}) error {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("tracing")
+	}
+
+	if !castx.ToBool(_settings["enabled"]) {
+		return nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch strings.ToLower(castx.ToString(_settings["exporter"])) {
+	case "otlp":
+		opts := []otlptracehttp.Option{}
+
+		if s1 := castx.ToString(_settings["endpoint"]); s1 != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(s1))
+		}
+
+		if s1 := castx.ToString(_settings["urlPath"]); s1 != "" {
+			opts = append(opts, otlptracehttp.WithURLPath(s1))
+		}
+
+		if castx.ToBool(_settings["insecure"]) {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		if headers := castx.ToStringMapString(_settings["headers"]); len(headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(headers))
+		}
+
+		exporter, err = otlptracehttp.New(context.Background(), opts...)
+	default:
+		var w io.Writer = os.Stdout
+
+		if writer, ok := _settings["writer"].(io.Writer); ok {
+			w = writer
+		}
+
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	sampleRatio := 1.0
+
+	if n1, err := castx.ToFloat64E(_settings["sampleRatio"]); err == nil && n1 >= 0 && n1 <= 1 {
+		sampleRatio = n1
+	}
+
+	WithTracingExporter(exporter, castx.ToString(_settings["serviceName"]), sampleRatio)
+	return nil
+}
+
+func WithTracingExporter(exporter sdktrace.SpanExporter, serviceName string, sampleRatio ...float64) {
+	ratio := 1.0
+
+	if len(sampleRatio) > 0 {
+		ratio = sampleRatio[0]
+	}
+
+	if serviceName == "" {
+		serviceName = "mgboot"
+	}
+
+	var processor sdktrace.TracerProviderOption
+
+	if _, ok := exporter.(*stdouttrace.Exporter); ok {
+		processor = sdktrace.WithSyncer(exporter)
+	} else {
+		processor = sdktrace.WithBatcher(exporter)
+	}
+
+	tracerProvider = sdktrace.NewTracerProvider(
+		processor,
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewWithAttributes("", attribute.String("service.name", serviceName))),
+	)
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	cachex.TracingEnabled(true)
+}
+
+func TracingEnabled() bool {
+	return tracerProvider != nil
+}
+
+func ShutdownTracing(timeout ...time.Duration) error {
+	if tracerProvider == nil {
+		return nil
+	}
+
+	_timeout := 5 * time.Second
+
+	if len(timeout) > 0 && timeout[0] > 0 {
+		_timeout = timeout[0]
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), _timeout)
+	defer cancel()
+	return tracerProvider.Shutdown(ctx)
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer("github.com/meiguonet/mgboot-go-fiber")
+}
+
+// @param parent *fiber.Ctx|*TraceContext|context.Context
+func StartSpan(parent interface{}, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(spanParentContext(parent), name, opts...)
+}
+
+// GetSpanContext returns the context carrying the server span started by MidTracing,
+// use it as the parent of custom spans.
+func GetSpanContext(ctx *fiber.Ctx) context.Context {
+	return spanParentContext(ctx)
+}
+
+func TraceContextFromSpan(span trace.Span, requestId ...string) *TraceContext {
+	sc := span.SpanContext()
+
+	if !sc.IsValid() {
+		return nil
+	}
+
+	tc := &TraceContext{
+		traceId: sc.TraceID().String(),
+		spanId:  sc.SpanID().String(),
+		flags:   byte(sc.TraceFlags()),
+	}
+
+	if len(requestId) > 0 && requestId[0] != "" {
+		tc.requestId = requestId[0]
+	} else {
+		tc.requestId = tc.traceId
+	}
+
+	return tc
+}
+
+func (tc *TraceContext) SpanContext() trace.SpanContext {
+	traceId, _ := trace.TraceIDFromHex(tc.traceId)
+	spanId, _ := trace.SpanIDFromHex(tc.spanId)
+	state, _ := trace.ParseTraceState(tc.traceState)
+
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceId,
+		SpanID:     spanId,
+		TraceFlags: trace.TraceFlags(tc.flags),
+		TraceState: state,
+		Remote:     true,
+	})
+}
+
+func spanParentContext(parent interface{}) context.Context {
+	switch p := parent.(type) {
+	case *fiber.Ctx:
+		if p == nil {
+			break
+		}
+
+		if c, ok := p.Locals("SpanContext").(context.Context); ok {
+			return c
+		}
+
+		if tc := GetTraceContext(p); tc != nil {
+			return trace.ContextWithRemoteSpanContext(context.Background(), tc.SpanContext())
+		}
+	case *TraceContext:
+		if p != nil {
+			return trace.ContextWithRemoteSpanContext(context.Background(), p.SpanContext())
+		}
+	case context.Context:
+		if p != nil {
+			return p
+		}
+	}
+
+	return context.Background()
+}
@@ -0,0 +1,328 @@
+package mgboot
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"hash"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var uploadExtRegexp = regexp.MustCompile(`^\.[A-Za-z0-9]{1,10}$`)
+
+type UploadSink interface {
+	Save(fieldName, fileName string, r io.Reader) (location string, err error)
+	Remove(location string) error
+}
+
+type tempDirUploadSink struct {
+	dir string
+}
+
+func NewTempDirUploadSink(dir ...string) *tempDirUploadSink {
+	var _dir string
+
+	if len(dir) > 0 && dir[0] != "" {
+		_dir = dir[0]
+	} else {
+		_dir = GetUploadSettings().TempDir()
+	}
+
+	return &tempDirUploadSink{dir: _dir}
+}
+
+func (s *tempDirUploadSink) Save(_, fileName string, r io.Reader) (string, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return "", err
+	}
+
+	ext := filepath.Ext(fileName)
+
+	if !uploadExtRegexp.MatchString(ext) {
+		ext = ""
+	}
+
+	f, err := ioutil.TempFile(s.dir, "upload-*"+ext)
+
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	if err = f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+func (s *tempDirUploadSink) Remove(location string) error {
+	return os.Remove(location)
+}
+
+var uploadSettings *UploadSettings
+
+func WithUploadSettings(settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("upload")
+	}
+
+	uploadSettings = NewUploadSettings(_settings)
+}
+
+func GetUploadSettings() *UploadSettings {
+	if uploadSettings == nil {
+		return NewUploadSettings(map[string]interface{}{})
+	}
+
+	return uploadSettings
+}
+
+func StreamingBodyConfig(config ...fiber.Config) fiber.Config {
+	var cfg fiber.Config
+
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	cfg.StreamRequestBody = true
+	cfg.DisablePreParseMultipartForm = true
+
+	if maxBodySize := int(GetUploadSettings().MaxBodySize()); cfg.BodyLimit < maxBodySize {
+		cfg.BodyLimit = maxBodySize
+	}
+
+	return cfg
+}
+
+// @param args *UploadSettings|UploadSink
+func StreamMultipart(ctx *fiber.Ctx, args ...interface{}) (*MultipartUpload, error) {
+	var settings *UploadSettings
+	var sink UploadSink
+
+	for _, arg := range args {
+		if st, ok := arg.(*UploadSettings); ok && st != nil {
+			settings = st
+		} else if s1, ok := arg.(UploadSink); ok && s1 != nil {
+			sink = s1
+		}
+	}
+
+	if settings == nil {
+		settings = GetUploadSettings()
+	}
+
+	if sink == nil {
+		sink = NewTempDirUploadSink(settings.TempDir())
+	}
+
+	maxBodySize := settings.MaxBodySize()
+
+	if n1, ok := ctx.Locals("BodyLimit").(int64); ok && n1 > 0 && n1 < maxBodySize {
+		maxBodySize = n1
+	}
+
+	if contentLength := int64(ctx.Request().Header.ContentLength()); contentLength > maxBodySize {
+		return nil, NewBodyLimitError("maxBodySize", maxBodySize)
+	}
+
+	boundary := string(ctx.Request().Header.MultipartFormBoundary())
+
+	if boundary == "" {
+		return nil, NewValidateError("请使用 multipart/form-data 格式提交数据", true)
+	}
+
+	var body io.Reader
+
+	if ctx.Request().IsBodyStream() {
+		body = ctx.Context().RequestBodyStream()
+	} else {
+		body = bytes.NewReader(ctx.Body())
+	}
+
+	body = &limitedUploadReader{r: body, remaining: maxBodySize, err: NewBodyLimitError("maxBodySize", maxBodySize)}
+	reader := multipart.NewReader(body, boundary)
+
+	upload := &MultipartUpload{
+		fields: map[string][]string{},
+		files:  map[string][]*UploadedFile{},
+		sink:   sink,
+	}
+
+	var fieldCount int
+
+	for {
+		part, err := reader.NextPart()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			upload.Cleanup()
+			return nil, toUploadError(err)
+		}
+
+		fieldName := part.FormName()
+
+		if fieldName == "" {
+			part.Close()
+			continue
+		}
+
+		if part.FileName() == "" {
+			fieldCount++
+
+			if fieldCount > settings.MaxFields() {
+				part.Close()
+				upload.Cleanup()
+				return nil, NewBodyLimitError("maxFields", int64(settings.MaxFields()))
+			}
+
+			lr := &limitedUploadReader{r: part, remaining: settings.MaxFieldSize(), err: NewBodyLimitError("maxFieldSize", settings.MaxFieldSize())}
+			buf, err := ioutil.ReadAll(lr)
+			part.Close()
+
+			if err != nil {
+				upload.Cleanup()
+				return nil, toUploadError(err)
+			}
+
+			upload.fields[fieldName] = append(upload.fields[fieldName], string(buf))
+			continue
+		}
+
+		if upload.FileCount() >= settings.MaxFiles() {
+			part.Close()
+			upload.Cleanup()
+			return nil, NewBodyLimitError("maxFiles", int64(settings.MaxFiles()))
+		}
+
+		file, err := saveUploadPart(part, settings, sink)
+		part.Close()
+
+		if err != nil {
+			upload.Cleanup()
+			return nil, toUploadError(err)
+		}
+
+		upload.files[fieldName] = append(upload.files[fieldName], file)
+	}
+
+	return upload, nil
+}
+
+func saveUploadPart(part *multipart.Part, settings *UploadSettings, sink UploadSink) (*UploadedFile, error) {
+	hashes := map[string]hash.Hash{}
+	writers := make([]io.Writer, 0)
+
+	for _, algo := range settings.Checksums() {
+		var h hash.Hash
+
+		switch algo {
+		case "md5":
+			h = md5.New()
+		case "sha1":
+			h = sha1.New()
+		case "sha256":
+			h = sha256.New()
+		default:
+			continue
+		}
+
+		hashes[algo] = h
+		writers = append(writers, h)
+	}
+
+	lr := &limitedUploadReader{r: part, remaining: settings.MaxFileSize(), err: NewBodyLimitError("maxFileSize", settings.MaxFileSize())}
+	var r io.Reader = lr
+
+	if len(writers) > 0 {
+		r = io.TeeReader(lr, io.MultiWriter(writers...))
+	}
+
+	location, err := sink.Save(part.FormName(), filepath.Base(part.FileName()), r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	checksums := map[string]string{}
+
+	for algo, h := range hashes {
+		checksums[algo] = hex.EncodeToString(h.Sum(nil))
+	}
+
+	return &UploadedFile{
+		fieldName:   part.FormName(),
+		fileName:    filepath.Base(part.FileName()),
+		contentType: part.Header.Get(fiber.HeaderContentType),
+		size:        lr.read,
+		location:    location,
+		checksums:   checksums,
+	}, nil
+}
+
+func toUploadError(err error) error {
+	var ex BodyLimitError
+
+	if errors.As(err, &ex) {
+		return ex
+	}
+
+	if _, ok := err.(ValidateError); ok {
+		return err
+	}
+
+	return NewValidateError("上传数据格式错误", true)
+}
+
+type limitedUploadReader struct {
+	r         io.Reader
+	remaining int64
+	read      int64
+	err       error
+}
+
+func (lr *limitedUploadReader) Read(p []byte) (int, error) {
+	if lr.remaining < 0 {
+		return 0, lr.err
+	}
+
+	if int64(len(p)) > lr.remaining+1 {
+		p = p[:lr.remaining+1]
+	}
+
+	n, err := lr.r.Read(p)
+	lr.remaining -= int64(n)
+	lr.read += int64(n)
+
+	if lr.remaining < 0 {
+		lr.read += lr.remaining
+		return n + int(lr.remaining), lr.err
+	}
+
+	return n, err
+}
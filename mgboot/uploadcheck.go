@@ -0,0 +1,262 @@
+package mgboot
+
+import (
+	"bytes"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/mimex"
+	"github.com/meiguonet/mgboot-go-common/util/numberx"
+	"github.com/meiguonet/mgboot-go-common/util/stringx"
+	"github.com/meiguonet/mgboot-go-fiber/enum/UploadErrno"
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var uploadMessages = map[string]map[int]string{
+	"zh-CN": {
+		UploadErrno.NoFile:              "没有文件被上传",
+		UploadErrno.FileTooLarge:        "文件大小超出限制",
+		UploadErrno.FileTooSmall:        "文件大小不能小于{min}",
+		UploadErrno.ExtensionNotAllowed: "不支持的文件类型",
+		UploadErrno.MimeTypeNotAllowed:  "不支持的文件类型",
+		UploadErrno.MimeTypeMismatch:    "文件内容与扩展名不符",
+		UploadErrno.InvalidImage:        "不是有效的图片文件",
+		UploadErrno.ImageSizeMismatch:   "请上传{width}x{height}的图片",
+		UploadErrno.ImageTooSmall:       "图片尺寸不能小于{width}x{height}",
+		UploadErrno.ImageTooLarge:       "图片尺寸不能大于{width}x{height}",
+		UploadErrno.ImageRatioMismatch:  "请上传{width}:{height}比例的图片",
+	},
+	"en": {
+		UploadErrno.NoFile:              "no file was uploaded",
+		UploadErrno.FileTooLarge:        "file size exceeds the limit",
+		UploadErrno.FileTooSmall:        "file size must be at least {min}",
+		UploadErrno.ExtensionNotAllowed: "file type is not allowed",
+		UploadErrno.MimeTypeNotAllowed:  "file type is not allowed",
+		UploadErrno.MimeTypeMismatch:    "file contents do not match its extension",
+		UploadErrno.InvalidImage:        "not a valid image file",
+		UploadErrno.ImageSizeMismatch:   "image must be {width}x{height}",
+		UploadErrno.ImageTooSmall:       "image must be at least {width}x{height}",
+		UploadErrno.ImageTooLarge:       "image must be at most {width}x{height}",
+		UploadErrno.ImageRatioMismatch:  "image aspect ratio must be {width}:{height}",
+	},
+}
+
+func WithUploadMessages(locale string, messages map[int]string) {
+	if _, ok := uploadMessages[locale]; !ok {
+		uploadMessages[locale] = map[int]string{}
+	}
+
+	for errno, msg := range messages {
+		uploadMessages[locale][errno] = msg
+	}
+}
+
+// @param arg0 *multipart.FileHeader|*UploadedFile
+func ValidateUploadedFile(arg0 interface{}, opts map[string]interface{}) error {
+	var fileName string
+	var fileSize int64
+	var open func() (io.ReadCloser, error)
+	var fh *multipart.FileHeader
+
+	if f, ok := arg0.(*multipart.FileHeader); ok && f != nil {
+		fh = f
+		fileName = f.Filename
+		fileSize = f.Size
+
+		open = func() (io.ReadCloser, error) {
+			return f.Open()
+		}
+	} else if f, ok := arg0.(*UploadedFile); ok && f != nil {
+		fileName = f.FileName()
+		fileSize = f.Size()
+
+		open = func() (io.ReadCloser, error) {
+			return os.Open(f.Location())
+		}
+	}
+
+	field := castx.ToString(opts["field"])
+
+	newError := func(errno int, params ...map[string]interface{}) UploadError {
+		_params := map[string]interface{}{}
+
+		if len(params) > 0 {
+			_params = params[0]
+		}
+
+		_params["field"] = field
+		return NewUploadError(errno, _params)
+	}
+
+	if open == nil {
+		return newError(UploadErrno.NoFile)
+	}
+
+	maxFileSize := toDataSizeOrDefault(opts["maxFileSize"], toDataSizeOrDefault(opts["fileSizeLimit"], 0))
+
+	if maxFileSize > 0 && fileSize > maxFileSize {
+		return newError(UploadErrno.FileTooLarge, map[string]interface{}{"max": maxFileSize})
+	}
+
+	if minFileSize := toDataSizeOrDefault(opts["minFileSize"], 0); minFileSize > 0 && fileSize < minFileSize {
+		return newError(UploadErrno.FileTooSmall, map[string]interface{}{"min": opts["minFileSize"]})
+	}
+
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(fileName), "."))
+	allowedExtensions := toUploadOptionList(opts["allowedExtensions"])
+
+	if len(allowedExtensions) > 0 && !matchUploadOption(ext, allowedExtensions) {
+		return newError(UploadErrno.ExtensionNotAllowed, map[string]interface{}{"extension": ext})
+	}
+
+	rc, err := open()
+
+	if err != nil {
+		return err
+	}
+
+	defer rc.Close()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(rc, head)
+	head = head[:n]
+	mimeType := baseMimeType(mimex.GetMimeType(head))
+	allowedMimeTypes := toUploadOptionList(opts["allowedMimeTypes"])
+
+	if len(allowedMimeTypes) > 0 && !matchUploadOption(mimeType, allowedMimeTypes) {
+		return newError(UploadErrno.MimeTypeNotAllowed, map[string]interface{}{"mimeType": mimeType})
+	}
+
+	if len(allowedExtensions) > 0 || len(allowedMimeTypes) > 0 {
+		extMimeType := baseMimeType(mime.TypeByExtension("." + ext))
+
+		if isImageMimeMismatch(extMimeType, mimeType) {
+			return newError(UploadErrno.MimeTypeMismatch, map[string]interface{}{"mimeType": mimeType, "extension": ext})
+		}
+	}
+
+	if !castx.ToBool(opts["checkImage"]) {
+		return nil
+	}
+
+	var imageInfo map[string]interface{}
+
+	if fn, ok := opts["imageInfoFunc"].(ImageInfoGetFunc); ok && fn != nil && fh != nil {
+		imageInfo = fn(fh)
+	} else {
+		imageInfo = GetImageInfo(io.MultiReader(bytes.NewReader(head), rc))
+	}
+
+	width := castx.ToInt(imageInfo["width"])
+	height := castx.ToInt(imageInfo["height"])
+
+	if width < 1 || height < 1 || castx.ToString(imageInfo["mimeType"]) == "" {
+		return newError(UploadErrno.InvalidImage)
+	}
+
+	if n1, n2 := parseUploadDimension(opts["imageSizeLimit"], `x`); n1 > 0 && n2 > 0 && (width != n1 || height != n2) {
+		return newError(UploadErrno.ImageSizeMismatch, map[string]interface{}{"width": n1, "height": n2})
+	}
+
+	if n1, n2 := parseUploadDimension(opts["minImageSize"], `x`); (n1 > 0 && width < n1) || (n2 > 0 && height < n2) {
+		return newError(UploadErrno.ImageTooSmall, map[string]interface{}{"width": n1, "height": n2})
+	}
+
+	if n1, n2 := parseUploadDimension(opts["maxImageSize"], `x`); (n1 > 0 && width > n1) || (n2 > 0 && height > n2) {
+		return newError(UploadErrno.ImageTooLarge, map[string]interface{}{"width": n1, "height": n2})
+	}
+
+	if n1, n2 := parseUploadDimension(opts["imageRatioLimit"], `:`); n1 > 0 && n2 > 0 {
+		n3 := numberx.Ojld(width, height)
+
+		if width/n3 != n1 || height/n3 != n2 {
+			return newError(UploadErrno.ImageRatioMismatch, map[string]interface{}{"width": n1, "height": n2})
+		}
+	}
+
+	return nil
+}
+
+func uploadMessage(errno int, locale ...string) string {
+	_locale := validateLocale
+
+	if len(locale) > 0 && locale[0] != "" {
+		_locale = locale[0]
+	}
+
+	if msg := uploadMessages[_locale][errno]; msg != "" {
+		return msg
+	}
+
+	return uploadMessages["zh-CN"][errno]
+}
+
+func toUploadOptionList(value interface{}) []string {
+	if s1, ok := value.(string); ok {
+		return toLowerSlice(strings.Split(s1, ","))
+	}
+
+	items := make([]string, 0)
+
+	for _, s1 := range toLowerSlice(castx.ToStringSlice(value)) {
+		items = append(items, strings.TrimPrefix(s1, "."))
+	}
+
+	return items
+}
+
+func matchUploadOption(value string, options []string) bool {
+	value = strings.TrimPrefix(value, ".")
+
+	for _, s1 := range options {
+		s1 = strings.TrimPrefix(s1, ".")
+
+		if s1 == value || s1 == "*" || s1 == "*/*" {
+			return true
+		}
+
+		if strings.HasSuffix(s1, "/*") && strings.HasPrefix(value, strings.TrimSuffix(s1, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func baseMimeType(mimeType string) string {
+	if idx := strings.Index(mimeType, ";"); idx >= 0 {
+		mimeType = mimeType[:idx]
+	}
+
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
+
+func isImageMimeMismatch(extMimeType, mimeType string) bool {
+	if extMimeType == "" || mimeType == "" || extMimeType == mimeType {
+		return false
+	}
+
+	if mimeType == "application/octet-stream" || strings.HasPrefix(mimeType, "text/") {
+		return strings.HasPrefix(extMimeType, "image/") && extMimeType != "image/svg+xml"
+	}
+
+	return strings.HasPrefix(extMimeType, "image/") || strings.HasPrefix(mimeType, "image/")
+}
+
+func parseUploadDimension(value interface{}, sep string) (int, int) {
+	s1 := strings.TrimSpace(castx.ToString(value))
+
+	if s1 == "" {
+		return 0, 0
+	}
+
+	parts := stringx.SplitWithRegexp(s1, `[\x20\t]*`+sep+`[\x20\t]*`)
+
+	if len(parts) < 2 {
+		return 0, 0
+	}
+
+	return castx.ToInt(parts[0]), castx.ToInt(parts[1])
+}
@@ -0,0 +1,776 @@
+package mgboot
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/stringx"
+	"github.com/meiguonet/mgboot-go-common/util/validatex"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type ValidateRuleFunc func(field reflect.Value, param string, parent reflect.Value) bool
+
+type validateRule struct {
+	name  string
+	param string
+}
+
+var validateLocale = "zh-CN"
+var validateRules = map[string]ValidateRuleFunc{}
+var validateRegexps = map[string]*regexp.Regexp{}
+var validateMu = &sync.RWMutex{}
+
+var builtinValidateRules = map[string]bool{
+	"omitempty": true, "required": true, "required_with": true, "required_without": true, "dive": true,
+	"min": true, "max": true, "len": true, "eq": true, "ne": true, "gt": true, "gte": true, "lt": true, "lte": true,
+	"oneof": true, "email": true, "mobile": true, "idcard": true, "url": true, "ip": true, "alpha": true,
+	"numeric": true, "alnum": true, "regexp": true, "eqfield": true, "nefield": true, "gtfield": true,
+	"gtefield": true, "ltfield": true, "ltefield": true,
+}
+
+var validateMessages = map[string]map[string]string{
+	"zh-CN": {
+		"default":          "格式不正确",
+		"required":         "必须填写",
+		"required_with":    "必须填写",
+		"required_without": "必须填写",
+		"min":              "不能小于{param}",
+		"min_len":          "长度不能小于{param}",
+		"max":              "不能大于{param}",
+		"max_len":          "长度不能大于{param}",
+		"len":              "必须等于{param}",
+		"len_len":          "长度必须等于{param}",
+		"eq":               "必须等于{param}",
+		"ne":               "不能等于{param}",
+		"gt":               "必须大于{param}",
+		"gte":              "必须大于或等于{param}",
+		"lt":               "必须小于{param}",
+		"lte":              "必须小于或等于{param}",
+		"oneof":            "必须是[{param}]中的一个",
+		"email":            "不是有效的邮箱地址",
+		"mobile":           "不是有效的手机号码",
+		"idcard":           "不是有效的身份证号码",
+		"url":              "不是有效的网址",
+		"ip":               "不是有效的IP地址",
+		"alpha":            "只能包含字母",
+		"numeric":          "只能包含数字",
+		"alnum":            "只能包含字母和数字",
+		"regexp":           "格式不正确",
+		"eqfield":          "必须与{param}一致",
+		"nefield":          "不能与{param}相同",
+		"gtfield":          "必须大于{param}",
+		"gtefield":         "必须大于或等于{param}",
+		"ltfield":          "必须小于{param}",
+		"ltefield":         "必须小于或等于{param}",
+	},
+	"en": {
+		"default":          "is invalid",
+		"required":         "is required",
+		"required_with":    "is required",
+		"required_without": "is required",
+		"min":              "must be at least {param}",
+		"min_len":          "must be at least {param} characters or items",
+		"max":              "must be at most {param}",
+		"max_len":          "must be at most {param} characters or items",
+		"len":              "must equal {param}",
+		"len_len":          "must be exactly {param} characters or items",
+		"eq":               "must equal {param}",
+		"ne":               "must not equal {param}",
+		"gt":               "must be greater than {param}",
+		"gte":              "must be greater than or equal to {param}",
+		"lt":               "must be less than {param}",
+		"lte":              "must be less than or equal to {param}",
+		"oneof":            "must be one of [{param}]",
+		"email":            "is not a valid email address",
+		"mobile":           "is not a valid mobile number",
+		"idcard":           "is not a valid ID card number",
+		"url":              "is not a valid URL",
+		"ip":               "is not a valid IP address",
+		"alpha":            "may only contain letters",
+		"numeric":          "may only contain digits",
+		"alnum":            "may only contain letters and digits",
+		"regexp":           "has an invalid format",
+		"eqfield":          "must match {param}",
+		"nefield":          "must differ from {param}",
+		"gtfield":          "must be greater than {param}",
+		"gtefield":         "must be greater than or equal to {param}",
+		"ltfield":          "must be less than {param}",
+		"ltefield":         "must be less than or equal to {param}",
+	},
+}
+
+func ValidateLocale(locale ...string) string {
+	if len(locale) > 0 && locale[0] != "" {
+		validateLocale = locale[0]
+	}
+
+	return validateLocale
+}
+
+func WithValidateRule(name string, fn ValidateRuleFunc, msg ...map[string]string) {
+	validateMu.Lock()
+	defer validateMu.Unlock()
+	validateRules[name] = fn
+
+	if len(msg) < 1 {
+		return
+	}
+
+	for locale, s1 := range msg[0] {
+		if _, ok := validateMessages[locale]; !ok {
+			validateMessages[locale] = map[string]string{}
+		}
+
+		validateMessages[locale][name] = s1
+	}
+}
+
+func WithValidateRuleChecker(checker validatex.RuleChecker, msg ...map[string]string) {
+	WithValidateRule(checker.GetRuleName(), func(field reflect.Value, param string, _ reflect.Value) bool {
+		value := castx.ToString(field.Interface())
+
+		if param == "" {
+			return checker.Check(value)
+		}
+
+		return checker.Check(value, param)
+	}, msg...)
+}
+
+func WithValidateMessages(locale string, messages map[string]string) {
+	validateMu.Lock()
+	defer validateMu.Unlock()
+
+	if _, ok := validateMessages[locale]; !ok {
+		validateMessages[locale] = map[string]string{}
+	}
+
+	for rule, msg := range messages {
+		validateMessages[locale][rule] = msg
+	}
+}
+
+func ValidateStruct(dto interface{}, locale ...string) error {
+	rv := reflect.ValueOf(dto)
+
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return errors.New("in mgboot.ValidateStruct function, dto is nil")
+		}
+
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return errors.New("in mgboot.ValidateStruct function, dto must be a struct")
+	}
+
+	_locale := validateLocale
+
+	if len(locale) > 0 && locale[0] != "" {
+		_locale = locale[0]
+	}
+
+	validateErrors := map[string]string{}
+
+	if err := validateStructValue(rv, "", _locale, validateErrors); err != nil {
+		return fmt.Errorf("in mgboot.ValidateStruct function, %v", err)
+	}
+
+	if len(validateErrors) > 0 {
+		return NewValidateError(validateErrors)
+	}
+
+	return nil
+}
+
+func resolveValidateLocale(ctx *fiber.Ctx) string {
+	acceptLanguage := ctx.Get(fiber.HeaderAcceptLanguage)
+
+	if acceptLanguage == "" {
+		return validateLocale
+	}
+
+	validateMu.RLock()
+	defer validateMu.RUnlock()
+
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		lang := strings.TrimSpace(stringx.SubstringBefore(part, ";"))
+
+		if lang == "" {
+			continue
+		}
+
+		for locale := range validateMessages {
+			if strings.EqualFold(locale, lang) {
+				return locale
+			}
+		}
+
+		for locale := range validateMessages {
+			if strings.EqualFold(stringx.SubstringBefore(locale, "-"), stringx.SubstringBefore(lang, "-")) {
+				return locale
+			}
+		}
+	}
+
+	return validateLocale
+}
+
+func validateStructValue(rv reflect.Value, prefix, locale string, validateErrors map[string]string) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		fv := rv.Field(i)
+
+		if field.Anonymous && bindTagName(field) == "" && field.Tag.Get("validate") == "" {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+
+				fv = fv.Elem()
+			}
+
+			if fv.Kind() == reflect.Struct && fv.Type() != timeType {
+				if err := validateStructValue(fv, prefix, locale, validateErrors); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if field.Tag.Get("validate") == "-" {
+			continue
+		}
+
+		fieldPath := joinFieldPath(prefix, validateFieldName(field))
+		rules, err := parseValidateRules(field.Tag.Get("validate"))
+
+		if err != nil {
+			return fmt.Errorf("field %s of %s: %v", field.Name, rt.String(), err)
+		}
+
+		if err := validateFieldValue(fv, rv, field, rules, fieldPath, locale, validateErrors); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateFieldValue(
+	fv, parent reflect.Value,
+	field reflect.StructField,
+	rules []validateRule,
+	fieldPath, locale string,
+	validateErrors map[string]string,
+) error {
+	var diveRules []validateRule
+	var dive bool
+
+	for idx, rule := range rules {
+		if rule.name == "dive" {
+			dive = true
+			diveRules = rules[idx+1:]
+			rules = rules[:idx]
+			break
+		}
+	}
+
+	if !checkFieldRules(fv, parent, field, rules, fieldPath, locale, validateErrors) {
+		return nil
+	}
+
+	for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			return nil
+		}
+
+		fv = fv.Elem()
+	}
+
+	switch fv.Kind() {
+	case reflect.Struct:
+		if fv.Type() != timeType {
+			return validateStructValue(fv, fieldPath, locale, validateErrors)
+		}
+	case reflect.Slice, reflect.Array:
+		for idx := 0; idx < fv.Len(); idx++ {
+			itemPath := fmt.Sprintf("%s[%d]", fieldPath, idx)
+
+			if dive {
+				if err := validateFieldValue(fv.Index(idx), parent, field, diveRules, itemPath, locale, validateErrors); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			item := fv.Index(idx)
+
+			for item.Kind() == reflect.Ptr && !item.IsNil() {
+				item = item.Elem()
+			}
+
+			if item.Kind() == reflect.Struct && item.Type() != timeType {
+				if err := validateStructValue(item, itemPath, locale, validateErrors); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Map:
+		iter := fv.MapRange()
+
+		for iter.Next() {
+			itemPath := joinFieldPath(fieldPath, castx.ToString(iter.Key().Interface()))
+
+			if dive {
+				if err := validateFieldValue(iter.Value(), parent, field, diveRules, itemPath, locale, validateErrors); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			item := iter.Value()
+
+			for item.Kind() == reflect.Ptr && !item.IsNil() {
+				item = item.Elem()
+			}
+
+			if item.Kind() == reflect.Struct && item.Type() != timeType {
+				if err := validateStructValue(item, itemPath, locale, validateErrors); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkFieldRules(
+	fv, parent reflect.Value,
+	field reflect.StructField,
+	rules []validateRule,
+	fieldPath, locale string,
+	validateErrors map[string]string,
+) bool {
+	if len(rules) < 1 {
+		return true
+	}
+
+	empty := isEmptyValue(fv)
+
+	for _, rule := range rules {
+		if rule.name == "omitempty" && empty {
+			return true
+		}
+	}
+
+	for _, rule := range rules {
+		var passed bool
+
+		switch rule.name {
+		case "omitempty":
+			continue
+		case "required":
+			passed = !empty
+		case "required_with":
+			passed = !empty || isEmptyValue(siblingField(parent, rule.param))
+		case "required_without":
+			passed = !empty || !isEmptyValue(siblingField(parent, rule.param))
+		default:
+			if !indirectValue(fv).IsValid() {
+				continue
+			}
+
+			passed = checkValidateRule(indirectValue(fv), parent, rule)
+		}
+
+		if !passed {
+			validateErrors[fieldPath] = validateMessage(locale, rule, fv, field)
+			return false
+		}
+	}
+
+	return true
+}
+
+func checkValidateRule(fv, parent reflect.Value, rule validateRule) bool {
+	param := rule.param
+
+	switch rule.name {
+	case "min":
+		return compareValue(fv, param) >= 0
+	case "max":
+		return compareValue(fv, param) <= 0
+	case "len":
+		return compareValue(fv, param) == 0
+	case "eq":
+		if fv.Kind() == reflect.String {
+			return fv.String() == param
+		}
+
+		return compareValue(fv, param) == 0
+	case "ne":
+		if fv.Kind() == reflect.String {
+			return fv.String() != param
+		}
+
+		return compareValue(fv, param) != 0
+	case "gt":
+		return compareValue(fv, param) > 0
+	case "gte":
+		return compareValue(fv, param) >= 0
+	case "lt":
+		return compareValue(fv, param) < 0
+	case "lte":
+		return compareValue(fv, param) <= 0
+	case "oneof":
+		value := castx.ToString(fv.Interface())
+
+		for _, s1 := range strings.Fields(param) {
+			if s1 == value {
+				return true
+			}
+		}
+
+		return false
+	case "email":
+		return stringx.IsEmail(castx.ToString(fv.Interface()))
+	case "mobile":
+		return stringx.IsNationalMobileNumber(castx.ToString(fv.Interface()))
+	case "idcard":
+		return stringx.IsIdcard(castx.ToString(fv.Interface()))
+	case "url":
+		u, err := url.Parse(castx.ToString(fv.Interface()))
+		return err == nil && u.Scheme != "" && u.Host != ""
+	case "ip":
+		return net.ParseIP(castx.ToString(fv.Interface())) != nil
+	case "alpha":
+		return stringx.IsLetteric(castx.ToString(fv.Interface()))
+	case "numeric":
+		return stringx.IsNumeric(castx.ToString(fv.Interface()))
+	case "alnum":
+		return stringx.IsAlnum(castx.ToString(fv.Interface()))
+	case "regexp":
+		re := compileValidateRegexp(param)
+		return re != nil && re.MatchString(castx.ToString(fv.Interface()))
+	case "eqfield", "nefield", "gtfield", "gtefield", "ltfield", "ltefield":
+		other := indirectValue(siblingField(parent, param))
+
+		if !other.IsValid() {
+			return false
+		}
+
+		n1 := compareValues(fv, other)
+
+		switch rule.name {
+		case "eqfield":
+			return n1 == 0
+		case "nefield":
+			return n1 != 0
+		case "gtfield":
+			return n1 > 0
+		case "gtefield":
+			return n1 >= 0
+		case "ltfield":
+			return n1 < 0
+		default:
+			return n1 <= 0
+		}
+	}
+
+	validateMu.RLock()
+	fn, ok := validateRules[rule.name]
+	validateMu.RUnlock()
+
+	// unknown rules are reported by parseValidateRules
+	if !ok {
+		return true
+	}
+
+	return fn(fv, param, parent)
+}
+
+func compareValue(fv reflect.Value, param string) int {
+	switch fv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		var n1 int
+
+		if fv.Kind() == reflect.String {
+			n1 = utf8.RuneCountInString(fv.String())
+		} else {
+			n1 = fv.Len()
+		}
+
+		return compareFloat(float64(n1), castx.ToFloat64(param))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareFloat(float64(fv.Int()), castx.ToFloat64(param))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareFloat(float64(fv.Uint()), castx.ToFloat64(param))
+	case reflect.Float32, reflect.Float64:
+		return compareFloat(fv.Float(), castx.ToFloat64(param))
+	case reflect.Bool:
+		if fv.Bool() == castx.ToBool(param) {
+			return 0
+		}
+
+		return 1
+	}
+
+	if fv.Type() == timeType {
+		t2, err := parseBindTime(param, "")
+
+		if err != nil {
+			return 0
+		}
+
+		return compareValues(fv, reflect.ValueOf(t2))
+	}
+
+	return 0
+}
+
+func compareValues(v1, v2 reflect.Value) int {
+	if v1.Type() == timeType && v2.Type() == timeType {
+		t1 := v1.Interface().(time.Time)
+		t2 := v2.Interface().(time.Time)
+
+		if t1.Before(t2) {
+			return -1
+		}
+
+		if t1.After(t2) {
+			return 1
+		}
+
+		return 0
+	}
+
+	switch v1.Kind() {
+	case reflect.String:
+		return strings.Compare(v1.String(), castx.ToString(v2.Interface()))
+	case reflect.Bool:
+		if v1.Bool() == castx.ToBool(v2.Interface()) {
+			return 0
+		}
+
+		return 1
+	}
+
+	return compareFloat(castx.ToFloat64(v1.Interface()), castx.ToFloat64(v2.Interface()))
+}
+
+func compareFloat(n1, n2 float64) int {
+	if n1 < n2 {
+		return -1
+	}
+
+	if n1 > n2 {
+		return 1
+	}
+
+	return 0
+}
+
+func validateMessage(locale string, rule validateRule, fv reflect.Value, field reflect.StructField) string {
+	if msg := field.Tag.Get("msg"); msg != "" {
+		return formatValidateMessage(msg, rule, field)
+	}
+
+	key := rule.name
+	kind := indirectValue(fv).Kind()
+
+	switch rule.name {
+	case "min", "max", "len":
+		if kind == reflect.String || kind == reflect.Slice || kind == reflect.Map || kind == reflect.Array {
+			key += "_len"
+		}
+	}
+
+	validateMu.RLock()
+	defer validateMu.RUnlock()
+	messages := validateMessages[locale]
+
+	if len(messages) < 1 {
+		messages = validateMessages[validateLocale]
+	}
+
+	msg := messages[key]
+
+	if msg == "" {
+		msg = messages[rule.name]
+	}
+
+	if msg == "" {
+		msg = messages["default"]
+	}
+
+	return formatValidateMessage(msg, rule, field)
+}
+
+func formatValidateMessage(msg string, rule validateRule, field reflect.StructField) string {
+	label := field.Tag.Get("label")
+
+	if label == "" {
+		label = validateFieldName(field)
+	}
+
+	msg = strings.ReplaceAll(msg, "{param}", rule.param)
+	msg = strings.ReplaceAll(msg, "{field}", label)
+	return msg
+}
+
+// parseValidateRules returns the rules of tag and an error naming the first rule which is neither builtin
+// nor registered by WithValidateRule, so that a misspelled rule does not disable the validation
+func parseValidateRules(tag string) ([]validateRule, error) {
+	rules := make([]validateRule, 0)
+
+	if tag == "" || tag == "-" {
+		return rules, nil
+	}
+
+	parts := make([]string, 0)
+	sb := strings.Builder{}
+
+	for i := 0; i < len(tag); i++ {
+		if tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ',' {
+			sb.WriteByte(',')
+			i++
+			continue
+		}
+
+		if tag[i] == ',' {
+			parts = append(parts, sb.String())
+			sb.Reset()
+			continue
+		}
+
+		sb.WriteByte(tag[i])
+	}
+
+	parts = append(parts, sb.String())
+
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+
+		if p == "" {
+			continue
+		}
+
+		rule := validateRule{name: p}
+
+		if idx := strings.Index(p, "="); idx > 0 {
+			rule.name = strings.TrimSpace(p[:idx])
+			rule.param = strings.TrimSpace(p[idx+1:])
+		}
+
+		rules = append(rules, rule)
+	}
+
+	validateMu.RLock()
+	defer validateMu.RUnlock()
+
+	for _, rule := range rules {
+		if _, ok := validateRules[rule.name]; !ok && !builtinValidateRules[rule.name] {
+			return rules, fmt.Errorf("unknown validate rule %s", rule.name)
+		}
+	}
+
+	return rules, nil
+}
+
+func compileValidateRegexp(pattern string) *regexp.Regexp {
+	validateMu.RLock()
+	re, ok := validateRegexps[pattern]
+	validateMu.RUnlock()
+
+	if ok {
+		return re
+	}
+
+	re, err := regexp.Compile(pattern)
+
+	if err != nil {
+		re = nil
+	}
+
+	validateMu.Lock()
+	validateRegexps[pattern] = re
+	validateMu.Unlock()
+	return re
+}
+
+func siblingField(parent reflect.Value, name string) reflect.Value {
+	if !parent.IsValid() || parent.Kind() != reflect.Struct || name == "" {
+		return reflect.Value{}
+	}
+
+	if fv := parent.FieldByName(name); fv.IsValid() {
+		return fv
+	}
+
+	rt := parent.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		if validateFieldName(rt.Field(i)) == name {
+			return parent.Field(i)
+		}
+	}
+
+	return reflect.Value{}
+}
+
+func validateFieldName(field reflect.StructField) string {
+	if name := bindTagName(field); name != "" {
+		return name
+	}
+
+	return field.Name
+}
+
+func indirectValue(rv reflect.Value) reflect.Value {
+	for rv.IsValid() && (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+
+		rv = rv.Elem()
+	}
+
+	return rv
+}
+
+func isEmptyValue(rv reflect.Value) bool {
+	if !rv.IsValid() {
+		return true
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return rv.Len() == 0
+	}
+
+	return rv.IsZero()
+}
@@ -0,0 +1,437 @@
+package mgboot
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"math/bits"
+)
+
+var vp8lCodeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+type vp8lBitWriter struct {
+	buf   []byte
+	bits  uint64
+	nbits uint
+}
+
+func (w *vp8lBitWriter) writeBits(value uint32, n uint) {
+	w.bits |= uint64(value) << w.nbits
+	w.nbits += n
+
+	for w.nbits >= 8 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits >>= 8
+		w.nbits -= 8
+	}
+}
+
+func (w *vp8lBitWriter) writeCode(code *vp8lHuffmanCode, symbol int) {
+	if n := code.lengths[symbol]; n > 0 {
+		w.writeBits(code.codes[symbol], uint(n))
+	}
+}
+
+func (w *vp8lBitWriter) bytes() []byte {
+	if w.nbits > 0 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits = 0
+		w.nbits = 0
+	}
+
+	return w.buf
+}
+
+type vp8lHuffmanCode struct {
+	lengths []uint8
+	codes   []uint32
+}
+
+type vp8lToken struct {
+	argb     uint32
+	length   int
+	distCode int
+}
+
+// EncodeWebp encodes img as a lossless (VP8L) WebP image.
+func EncodeWebp(img image.Image) ([]byte, error) {
+	rect := img.Bounds()
+	width, height := rect.Dx(), rect.Dy()
+
+	if width < 1 || height < 1 || width > 16384 || height > 16384 {
+		return nil, errors.New("invalid webp image dimensions")
+	}
+
+	nrgba, ok := img.(*image.NRGBA)
+
+	if !ok || nrgba.Rect.Min != (image.Point{}) || nrgba.Stride != width*4 {
+		nrgba = image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(nrgba, nrgba.Rect, img, rect.Min, draw.Src)
+	}
+
+	pixels := make([]uint32, width*height)
+	var hasAlpha bool
+
+	for i := range pixels {
+		p := nrgba.Pix[i*4 : i*4+4]
+		pixels[i] = uint32(p[3])<<24 | uint32(p[0])<<16 | uint32(p[1])<<8 | uint32(p[2])
+
+		if p[3] != 0xff {
+			hasAlpha = true
+		}
+	}
+
+	tokens := vp8lTokenize(pixels, width)
+	green := make([]int, 256+24)
+	red := make([]int, 256)
+	blue := make([]int, 256)
+	alpha := make([]int, 256)
+	dist := make([]int, 40)
+
+	for _, t := range tokens {
+		if t.length == 0 {
+			green[(t.argb>>8)&0xff]++
+			red[(t.argb>>16)&0xff]++
+			blue[t.argb&0xff]++
+			alpha[t.argb>>24]++
+			continue
+		}
+
+		prefix, _, _ := vp8lPrefixEncode(t.length)
+		green[256+prefix]++
+		prefix, _, _ = vp8lPrefixEncode(t.distCode)
+		dist[prefix]++
+	}
+
+	w := &vp8lBitWriter{}
+	w.writeBits(0x2f, 8)
+	w.writeBits(uint32(width-1), 14)
+	w.writeBits(uint32(height-1), 14)
+
+	if hasAlpha {
+		w.writeBits(1, 1)
+	} else {
+		w.writeBits(0, 1)
+	}
+
+	w.writeBits(0, 3)
+	// no transform, no color cache, no meta prefix codes
+	w.writeBits(0, 1)
+	w.writeBits(0, 1)
+	w.writeBits(0, 1)
+
+	codes := make([]*vp8lHuffmanCode, 0, 5)
+
+	for _, freqs := range [][]int{green, red, blue, alpha, dist} {
+		codes = append(codes, vp8lWriteHuffmanCode(w, freqs))
+	}
+
+	for _, t := range tokens {
+		if t.length == 0 {
+			w.writeCode(codes[0], int((t.argb>>8)&0xff))
+			w.writeCode(codes[1], int((t.argb>>16)&0xff))
+			w.writeCode(codes[2], int(t.argb&0xff))
+			w.writeCode(codes[3], int(t.argb>>24))
+			continue
+		}
+
+		prefix, extraBits, extraValue := vp8lPrefixEncode(t.length)
+		w.writeCode(codes[0], 256+prefix)
+		w.writeBits(uint32(extraValue), extraBits)
+		prefix, extraBits, extraValue = vp8lPrefixEncode(t.distCode)
+		w.writeCode(codes[4], prefix)
+		w.writeBits(uint32(extraValue), extraBits)
+	}
+
+	data := w.bytes()
+	chunkSize := len(data)
+
+	if len(data)%2 == 1 {
+		data = append(data, 0)
+	}
+
+	buf := make([]byte, 20+len(data))
+	copy(buf[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(12+len(data)))
+	copy(buf[8:16], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(buf[16:20], uint32(chunkSize))
+	copy(buf[20:], data)
+	return buf, nil
+}
+
+func vp8lTokenize(pixels []uint32, width int) []vp8lToken {
+	tokens := make([]vp8lToken, 0, len(pixels)/2)
+	n := len(pixels)
+
+	for i := 0; i < n; {
+		var best, distCode int
+
+		if i >= 1 {
+			k := 0
+
+			for i+k < n && k < 4096 && pixels[i+k] == pixels[i+k-1] {
+				k++
+			}
+
+			best, distCode = k, 2
+		}
+
+		if i >= width {
+			k := 0
+
+			for i+k < n && k < 4096 && pixels[i+k] == pixels[i+k-width] {
+				k++
+			}
+
+			if k > best {
+				best, distCode = k, 1
+			}
+		}
+
+		if best >= 3 {
+			tokens = append(tokens, vp8lToken{length: best, distCode: distCode})
+			i += best
+			continue
+		}
+
+		tokens = append(tokens, vp8lToken{argb: pixels[i]})
+		i++
+	}
+
+	return tokens
+}
+
+func vp8lPrefixEncode(value int) (int, uint, int) {
+	v := value - 1
+
+	if v < 4 {
+		return v, 0, 0
+	}
+
+	highestBit := bits.Len(uint(v)) - 1
+	secondBit := (v >> uint(highestBit-1)) & 1
+	extraBits := uint(highestBit - 1)
+	return 2*highestBit + secondBit, extraBits, v & (1<<extraBits - 1)
+}
+
+func vp8lWriteHuffmanCode(w *vp8lBitWriter, freqs []int) *vp8lHuffmanCode {
+	used := make([]int, 0)
+
+	for symbol, freq := range freqs {
+		if freq > 0 {
+			used = append(used, symbol)
+		}
+	}
+
+	if len(used) < 1 {
+		used = append(used, 0)
+	}
+
+	code := &vp8lHuffmanCode{lengths: make([]uint8, len(freqs))}
+
+	if len(used) <= 2 && used[len(used)-1] < 256 {
+		w.writeBits(1, 1)
+		w.writeBits(uint32(len(used)-1), 1)
+
+		if used[0] < 2 {
+			w.writeBits(0, 1)
+			w.writeBits(uint32(used[0]), 1)
+		} else {
+			w.writeBits(1, 1)
+			w.writeBits(uint32(used[0]), 8)
+		}
+
+		if len(used) == 2 {
+			w.writeBits(uint32(used[1]), 8)
+			code.lengths[used[0]] = 1
+			code.lengths[used[1]] = 1
+		}
+
+		code.codes = huffmanCanonicalCodes(code.lengths)
+		return code
+	}
+
+	code.lengths = huffmanCodeLengths(freqs, 15)
+	code.codes = huffmanCanonicalCodes(code.lengths)
+	w.writeBits(0, 1)
+
+	clFreqs := make([]int, 19)
+	var clUsed int
+
+	for _, n := range code.lengths {
+		if clFreqs[n] == 0 {
+			clUsed++
+		}
+
+		clFreqs[n]++
+	}
+
+	if clUsed < 2 {
+		if clFreqs[0] == 0 {
+			clFreqs[0] = 1
+		} else {
+			clFreqs[1] = 1
+		}
+	}
+
+	clCode := &vp8lHuffmanCode{lengths: huffmanCodeLengths(clFreqs, 7)}
+	clCode.codes = huffmanCanonicalCodes(clCode.lengths)
+	num := 19
+
+	for num > 4 && clCode.lengths[vp8lCodeLengthOrder[num-1]] == 0 {
+		num--
+	}
+
+	w.writeBits(uint32(num-4), 4)
+
+	for i := 0; i < num; i++ {
+		w.writeBits(uint32(clCode.lengths[vp8lCodeLengthOrder[i]]), 3)
+	}
+
+	w.writeBits(0, 1)
+
+	for _, n := range code.lengths {
+		w.writeCode(clCode, int(n))
+	}
+
+	return code
+}
+
+func huffmanCodeLengths(freqs []int, maxLength int) []uint8 {
+	_freqs := append([]int{}, freqs...)
+
+	for {
+		lengths := huffmanBuildLengths(_freqs)
+		var max uint8
+
+		for _, n := range lengths {
+			if n > max {
+				max = n
+			}
+		}
+
+		if int(max) <= maxLength {
+			return lengths
+		}
+
+		for i, freq := range _freqs {
+			if freq > 0 {
+				_freqs[i] = (freq + 1) / 2
+			}
+		}
+	}
+}
+
+func huffmanBuildLengths(freqs []int) []uint8 {
+	type node struct {
+		freq   int
+		parent int
+	}
+
+	nodes := make([]node, 0, len(freqs)*2)
+	leaves := make([]int, len(freqs))
+	active := make([]int, 0, len(freqs))
+
+	for symbol, freq := range freqs {
+		leaves[symbol] = -1
+
+		if freq > 0 {
+			leaves[symbol] = len(nodes)
+			active = append(active, len(nodes))
+			nodes = append(nodes, node{freq: freq, parent: -1})
+		}
+	}
+
+	lengths := make([]uint8, len(freqs))
+
+	if len(active) == 1 {
+		for symbol, idx := range leaves {
+			if idx >= 0 {
+				lengths[symbol] = 1
+			}
+		}
+
+		return lengths
+	}
+
+	for len(active) > 1 {
+		var a, b int
+
+		if nodes[active[0]].freq <= nodes[active[1]].freq {
+			a, b = 0, 1
+		} else {
+			a, b = 1, 0
+		}
+
+		for i := 2; i < len(active); i++ {
+			freq := nodes[active[i]].freq
+
+			if freq < nodes[active[a]].freq {
+				a, b = i, a
+			} else if freq < nodes[active[b]].freq {
+				b = i
+			}
+		}
+
+		parent := len(nodes)
+		nodes = append(nodes, node{freq: nodes[active[a]].freq + nodes[active[b]].freq, parent: -1})
+		nodes[active[a]].parent = parent
+		nodes[active[b]].parent = parent
+
+		if a < b {
+			a, b = b, a
+		}
+
+		active = append(active[:a], active[a+1:]...)
+		active = append(active[:b], active[b+1:]...)
+		active = append(active, parent)
+	}
+
+	for symbol, idx := range leaves {
+		if idx < 0 {
+			continue
+		}
+
+		var depth uint8
+
+		for p := nodes[idx].parent; p >= 0; p = nodes[p].parent {
+			depth++
+		}
+
+		lengths[symbol] = depth
+	}
+
+	return lengths
+}
+
+func huffmanCanonicalCodes(lengths []uint8) []uint32 {
+	var counts [16]int
+
+	for _, n := range lengths {
+		if n > 0 {
+			counts[n]++
+		}
+	}
+
+	var nextCode [16]int
+	code := 0
+
+	for n := 1; n < 16; n++ {
+		code = (code + counts[n-1]) << 1
+		nextCode[n] = code
+	}
+
+	codes := make([]uint32, len(lengths))
+
+	for symbol, n := range lengths {
+		if n < 1 {
+			continue
+		}
+
+		codes[symbol] = uint32(bits.Reverse32(uint32(nextCode[n])) >> (32 - uint(n)))
+		nextCode[n]++
+	}
+
+	return codes
+}
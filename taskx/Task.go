@@ -1,8 +1,14 @@
 package taskx
 
+import "github.com/meiguonet/mgboot-go-fiber/mgboot"
+
 type Task interface {
 	GetTaskName() string
 	SetParams(params map[string]interface{})
 	GetTaskParams() map[string]interface{}
 	Run() bool
 }
+
+type TraceableTask interface {
+	SetTraceContext(tc *mgboot.TraceContext)
+}
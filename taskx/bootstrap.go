@@ -0,0 +1,66 @@
+package taskx
+
+import (
+	"context"
+	"errors"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-fiber/mgboot"
+	"github.com/robfig/cron/v3"
+	"sync/atomic"
+)
+
+// AppComponent schedules the cron tasks and the redismq handlers enabled by task.redismq.normal
+// and task.redismq.delayable in AppConf, a cron is created when crond is not given (with seconds field
+// if task.cronWithSeconds is set), the cron and mqBacklog (limited by task.redismq.maxBacklog) health checks
+// are registered, on shutdown the cron is stopped and the running tasks are waited for
+func AppComponent(crond ...*cron.Cron) mgboot.AppComponent {
+	var c *cron.Cron
+
+	if len(crond) > 0 && crond[0] != nil {
+		c = crond[0]
+	}
+
+	boot := func(_ *mgboot.Application) error {
+		if c == nil {
+			if AppConf.GetBoolean("task.cronWithSeconds") {
+				c = cron.New(cron.WithSeconds())
+			} else {
+				c = cron.New()
+			}
+		}
+
+		HandleCronTasks(c)
+
+		if AppConf.GetBoolean("task.redismq.normal") {
+			HandleRedismqNormalTasks(c)
+		}
+
+		if AppConf.GetBoolean("task.redismq.delayable") {
+			HandleRedismqDelayableTasks(c)
+		}
+
+		c.Start()
+		appCron = c
+		atomic.StoreInt32(&appCronRunning, 1)
+		mgboot.WithHealthCheck("cron", CronHealthCheck())
+
+		if AppConf.GetBoolean("task.redismq.normal") || AppConf.GetBoolean("task.redismq.delayable") {
+			mgboot.WithHealthCheck("mqBacklog", MqBacklogHealthCheck(AppConf.GetInt("task.redismq.maxBacklog")))
+		}
+
+		return nil
+	}
+
+	shutdown := func(ctx context.Context) error {
+		atomic.StoreInt32(&appCronRunning, 0)
+
+		select {
+		case <-c.Stop().Done():
+			return nil
+		case <-ctx.Done():
+			return errors.New("in taskx.AppComponent, timeout waiting for the running tasks")
+		}
+	}
+
+	return mgboot.NewAppComponent("tasks", mgboot.AppOrderTasks, boot, shutdown)
+}
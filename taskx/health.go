@@ -0,0 +1,88 @@
+package taskx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	"github.com/meiguonet/mgboot-go-dal/poolx"
+	"github.com/meiguonet/mgboot-go-fiber/cachex"
+	"github.com/meiguonet/mgboot-go-fiber/mgboot"
+	"github.com/robfig/cron/v3"
+	"sync/atomic"
+	"time"
+)
+
+var appCron *cron.Cron
+var appCronRunning int32
+
+// CronHealthCheck checks the cron of AppComponent is running and none of its entries is overdue by more than a minute
+func CronHealthCheck() mgboot.HealthCheckFunc {
+	return func(_ context.Context) (map[string]interface{}, error) {
+		if appCron == nil || atomic.LoadInt32(&appCronRunning) != 1 {
+			return nil, errors.New("cron scheduler not running")
+		}
+
+		entries := appCron.Entries()
+		details := map[string]interface{}{"entries": len(entries)}
+		deadline := time.Now().Add(-time.Minute)
+
+		for _, entry := range entries {
+			if !entry.Next.IsZero() && entry.Next.Before(deadline) {
+				return details, fmt.Errorf("cron scheduler stalled, entry %d is overdue since %s", entry.ID, entry.Next.Format(time.RFC3339))
+			}
+		}
+
+		return details, nil
+	}
+}
+
+// MqBacklogHealthCheck reports the number of the pending normal tasks and the overdue delayable tasks,
+// the check fails when the sum of them exceeds maxBacklog, it is never exceeded when maxBacklog < 1
+func MqBacklogHealthCheck(maxBacklog int) mgboot.HealthCheckFunc {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		pool := poolx.GetRedisPool()
+
+		if pool == nil {
+			return nil, errors.New("redis pool not initialized")
+		}
+
+		conn, err := pool.GetContext(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		defer conn.Close()
+		normal, err := redis.Int(conn.Do("LLEN", cachex.CacheKeyRedismqNormal()))
+
+		if err != nil {
+			return nil, err
+		}
+
+		key := cachex.CacheKeyRedismqDelayable()
+		delayable, err := redis.Int(conn.Do("ZCARD", key))
+
+		if err != nil {
+			return nil, err
+		}
+
+		overdue, err := redis.Int(conn.Do("ZCOUNT", key, "-inf", time.Now().Unix()))
+
+		if err != nil {
+			return nil, err
+		}
+
+		details := map[string]interface{}{
+			"normal":           normal,
+			"delayable":        delayable,
+			"delayableOverdue": overdue,
+		}
+
+		if maxBacklog > 0 && normal+overdue > maxBacklog {
+			return details, fmt.Errorf("mq backlog %d exceeds %d", normal+overdue, maxBacklog)
+		}
+
+		return details, nil
+	}
+}
@@ -0,0 +1,93 @@
+package taskx
+
+import (
+	"context"
+	"github.com/gomodule/redigo/redis"
+	"github.com/meiguonet/mgboot-go-dal/poolx"
+	"github.com/meiguonet/mgboot-go-fiber/cachex"
+	"github.com/meiguonet/mgboot-go-fiber/mgboot"
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+	"time"
+)
+
+var taskRunsTotal *prometheus.CounterVec
+var taskMetricsMu = &sync.RWMutex{}
+
+type mqQueueCollector struct {
+	depth *prometheus.Desc
+}
+
+func (c *mqQueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.depth
+}
+
+func (c *mqQueueCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	conn, err := poolx.GetRedisConnection(ctx)
+
+	if err != nil {
+		return
+	}
+
+	defer conn.Close()
+
+	if n1, err := redis.Int64(conn.Do("LLEN", cachex.CacheKeyRedismqNormal())); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.depth, prometheus.GaugeValue, float64(n1), "normal")
+	}
+
+	if n1, err := redis.Int64(conn.Do("ZCARD", cachex.CacheKeyRedismqDelayable())); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.depth, prometheus.GaugeValue, float64(n1), "delayable")
+	}
+}
+
+// WithMetrics registers the mq queue depth gauge and the task run counter,
+// call it after mgboot.WithMetricsSettings.
+func WithMetrics() error {
+	if !mgboot.MetricsEnabled() {
+		return nil
+	}
+
+	namespace := mgboot.MetricsNamespace()
+
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "task_runs_total",
+		Help:      "Total number of mq task runs by task, type and result.",
+	}, []string{"task", "type", "result"})
+
+	collector := &mqQueueCollector{
+		depth: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "mq_queue_depth"),
+			"Number of pending tasks in the mq queue.",
+			[]string{"queue"},
+			nil,
+		),
+	}
+
+	if err := mgboot.WithMetricsCollector(counter); err != nil {
+		return err
+	}
+
+	if err := mgboot.WithMetricsCollector(collector); err != nil {
+		return err
+	}
+
+	taskMetricsMu.Lock()
+	taskRunsTotal = counter
+	taskMetricsMu.Unlock()
+	return nil
+}
+
+func observeTaskRun(taskName, taskType, result string) {
+	taskMetricsMu.RLock()
+	counter := taskRunsTotal
+	taskMetricsMu.RUnlock()
+
+	if counter == nil {
+		return
+	}
+
+	counter.WithLabelValues(taskName, taskType, result).Inc()
+}
@@ -1,7 +1,9 @@
 package taskx
 
 import (
+	"context"
 	"fmt"
+	"github.com/gofiber/fiber/v2"
 	"github.com/meiguonet/mgboot-go-common/enum/DatetimeFormat"
 	"github.com/meiguonet/mgboot-go-common/logx"
 	"github.com/meiguonet/mgboot-go-common/util/castx"
@@ -10,6 +12,9 @@ import (
 	"github.com/meiguonet/mgboot-go-fiber/cachex"
 	"github.com/meiguonet/mgboot-go-fiber/mgboot"
 	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"strings"
 	"time"
 )
@@ -88,6 +93,8 @@ func RunCronTask(taskName string) {
 		CronTaskLogger().Info("run cron task: " + taskName)
 	}
 
+	_, span := mgboot.StartSpan(nil, "cron.run "+taskName, trace.WithAttributes(attribute.String("task.name", taskName)))
+	defer span.End()
 	task.Run()
 }
 
@@ -124,6 +131,31 @@ func RunMqTask(payload string) {
 		taskType = "normal"
 	}
 
+	tc := mgboot.TraceContextFromMap(map1)
+
+	_, span := mgboot.StartSpan(
+		tc,
+		"task.run "+taskName,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attribute.String("task.name", taskName), attribute.String("task.type", taskType)),
+	)
+
+	defer span.End()
+
+	if mgboot.TracingEnabled() {
+		var requestId string
+
+		if tc != nil {
+			requestId = tc.RequestId()
+		}
+
+		tc = mgboot.TraceContextFromSpan(span, requestId)
+	}
+
+	if t, ok := task.(TraceableTask); ok && tc != nil {
+		t.SetTraceContext(tc)
+	}
+
 	if mqTaskLogEnabled {
 		sb := []string{
 			fmt.Sprintf("run %s task: %s", taskType, taskName),
@@ -137,10 +169,18 @@ func RunMqTask(payload string) {
 			sb = append(sb, ", task params: " + jsonx.ToJson(taskParams))
 		}
 
-		MqTaskLogger().Info(strings.Join(sb, ""))
+		mgboot.NewContextLogger(MqTaskLogger(), tc).Info(strings.Join(sb, ""))
 	}
 
 	success := task.Run()
+	span.SetAttributes(attribute.Bool("task.success", success))
+
+	if success {
+		observeTaskRun(taskName, taskType, "success")
+	} else {
+		observeTaskRun(taskName, taskType, "failure")
+		span.SetStatus(codes.Error, "task run failed")
+	}
 
 	if mqTaskLogEnabled {
 		sb := make([]string, 0)
@@ -161,7 +201,7 @@ func RunMqTask(payload string) {
 			sb = append(sb, ", task params: " + jsonx.ToJson(taskParams))
 		}
 
-		MqTaskLogger().Info(strings.Join(sb, ""))
+		mgboot.NewContextLogger(MqTaskLogger(), tc).Info(strings.Join(sb, ""))
 	}
 
 	if success {
@@ -194,7 +234,8 @@ func RunMqTask(payload string) {
 		"retryInterval": retryDuration,
 	})
 
-	PublishDelayable(task, retryDuration, policy)
+	observeTaskRun(taskName, taskType, "retry")
+	PublishDelayable(task, retryDuration, policy, tc)
 }
 
 func WithCronTasks(task CronTask) {
@@ -218,11 +259,14 @@ func WithCronTasks(task CronTask) {
 	cronTasks = entries
 }
 
-func Publish(task Task, policy ...*retryPolicy) {
-	var rp *retryPolicy
+// @param args *retryPolicy|*fiber.Ctx|*mgboot.TraceContext
+func Publish(task Task, args ...interface{}) {
+	rp, tc := parsePublishArgs(args)
+	_, span := startPublishSpan(task, tc)
+	defer span.End()
 
-	if len(policy) > 0 {
-		rp = policy[0]
+	if mgboot.TracingEnabled() {
+		tc = publishTraceContext(span, tc)
 	}
 
 	payload := map[string]interface{}{
@@ -239,6 +283,12 @@ func Publish(task Task, policy ...*retryPolicy) {
 		payload["retryInterval"] = rp.retryInterval.Milliseconds()
 	}
 
+	if tc != nil {
+		for key, value := range tc.ToMap() {
+			payload[key] = value
+		}
+	}
+
 	conn, err := poolx.GetRedisConnection()
 
 	if err != nil {
@@ -266,14 +316,17 @@ func Publish(task Task, policy ...*retryPolicy) {
 		sb = append(sb, ", task params: " + jsonx.ToJson(task.GetTaskParams()))
 	}
 
-	MqTaskLogger().Info(strings.Join(sb, ""))
+	mgboot.NewContextLogger(MqTaskLogger(), tc).Info(strings.Join(sb, ""))
 }
 
-func PublishDelayable(task Task, runAfter time.Duration, policy ...*retryPolicy) {
-	var rp *retryPolicy
+// @param args *retryPolicy|*fiber.Ctx|*mgboot.TraceContext
+func PublishDelayable(task Task, runAfter time.Duration, args ...interface{}) {
+	rp, tc := parsePublishArgs(args)
+	_, span := startPublishSpan(task, tc)
+	defer span.End()
 
-	if len(policy) > 0 {
-		rp = policy[0]
+	if mgboot.TracingEnabled() {
+		tc = publishTraceContext(span, tc)
 	}
 
 	loc, _ := time.LoadLocation("Asia/Shanghai")
@@ -294,6 +347,12 @@ func PublishDelayable(task Task, runAfter time.Duration, policy ...*retryPolicy)
 		payload["retryInterval"] = rp.retryInterval.Milliseconds()
 	}
 
+	if tc != nil {
+		for key, value := range tc.ToMap() {
+			payload[key] = value
+		}
+	}
+
 	conn, err := poolx.GetRedisConnection()
 
 	if err != nil {
@@ -327,7 +386,44 @@ func PublishDelayable(task Task, runAfter time.Duration, policy ...*retryPolicy)
 		sb = append(sb, ", task params: " + jsonx.ToJson(task.GetTaskParams()))
 	}
 
-	MqTaskLogger().Info(strings.Join(sb, ""))
+	mgboot.NewContextLogger(MqTaskLogger(), tc).Info(strings.Join(sb, ""))
+}
+
+func parsePublishArgs(args []interface{}) (*retryPolicy, *mgboot.TraceContext) {
+	var rp *retryPolicy
+	var tc *mgboot.TraceContext
+
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case *retryPolicy:
+			rp = v
+		case *fiber.Ctx:
+			tc = mgboot.GetTraceContext(v)
+		case *mgboot.TraceContext:
+			tc = v
+		}
+	}
+
+	return rp, tc
+}
+
+func startPublishSpan(task Task, tc *mgboot.TraceContext) (context.Context, trace.Span) {
+	return mgboot.StartSpan(
+		tc,
+		"task.publish "+task.GetTaskName(),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attribute.String("task.name", task.GetTaskName())),
+	)
+}
+
+func publishTraceContext(span trace.Span, tc *mgboot.TraceContext) *mgboot.TraceContext {
+	var requestId string
+
+	if tc != nil {
+		requestId = tc.RequestId()
+	}
+
+	return mgboot.TraceContextFromSpan(span, requestId)
 }
 
 func HandleCronTasks(crond *cron.Cron) {